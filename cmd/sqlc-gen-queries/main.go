@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"runtime/debug"
@@ -27,25 +28,126 @@ func main() {
 				Sources: cli.EnvVars("SQLC_CATALOG_FILE"),
 				Value:   "schema.json",
 			},
+			&cli.StringFlag{
+				Name:    "catalog-source",
+				Usage:   "Source of the catalog: atlas (the catalog file) or ddl (the schema of each sql block).",
+				Sources: cli.EnvVars("SQLC_CATALOG_SOURCE"),
+				Value:   "atlas",
+				Validator: func(value string) error {
+					if value != "atlas" && value != "ddl" {
+						return fmt.Errorf("unsupported catalog source %q", value)
+					}
+					return nil
+				},
+			},
+			&cli.StringFlag{
+				Name:    "template-dir",
+				Usage:   "Directory of the templates that replace the built-in template or some of its blocks.",
+				Sources: cli.EnvVars("SQLC_TEMPLATE_DIR"),
+			},
+			&cli.BoolFlag{
+				Name:    "no-prune",
+				Usage:   "Keep the generated files of tables that are no longer generated.",
+				Sources: cli.EnvVars("SQLC_NO_PRUNE"),
+			},
+			&cli.BoolFlag{
+				Name:    "strict",
+				Usage:   "Fail when an include or exclude entry matches no table or query.",
+				Sources: cli.EnvVars("SQLC_STRICT"),
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the files and queries that would be written instead of writing them.",
+			},
+			&cli.BoolFlag{
+				Name:  "stdout",
+				Usage: "Print the generated SQL instead of writing the files.",
+			},
+			&cli.StringFlag{
+				Name:  "table",
+				Usage: "Print only the SQL of the given table (requires --stdout).",
+			},
 		},
 		ErrWriter: os.Stderr,
+		Commands: []*cli.Command{
+			{
+				Name:      "plugin",
+				Usage:     "Run as a sqlc process plugin",
+				UsageText: "sqlc-gen-queries plugin [method]",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					// sqlc passes the RPC method name as the only argument
+					if method := cmd.Args().First(); method != "" && method != sqlc.PluginMethod {
+						return fmt.Errorf("unsupported plugin method %q", method)
+					}
+
+					return sqlc.RunPlugin(os.Stdin, os.Stdout)
+				},
+			},
+			{
+				Name:      "check",
+				Usage:     "Check that the generated files are up to date without writing them",
+				UsageText: "sqlc-gen-queries [global options] check",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					generators, err := newGenerators(cmd)
+					if err != nil {
+						return err
+					}
+
+					diffs, err := generators.Check()
+					if err != nil {
+						return err
+					}
+
+					for _, diff := range diffs {
+						switch diff.Status {
+						case sqlc.DiffModified:
+							fmt.Fprint(cmd.Root().Writer, diff.Patch)
+						default:
+							fmt.Fprintf(cmd.Root().Writer, "%s file %s\n", diff.Status, diff.Path)
+						}
+					}
+
+					if len(diffs) > 0 {
+						return fmt.Errorf("%d generated files are out of date", len(diffs))
+					}
+
+					return nil
+				},
+			},
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			config, err := sqlc.LoadConfig(cmd.String("config-file"))
+			if cmd.Bool("dry-run") && cmd.Bool("stdout") {
+				return fmt.Errorf("--dry-run and --stdout cannot be used together")
+			}
+			if cmd.String("table") != "" && !cmd.Bool("stdout") {
+				return fmt.Errorf("--table requires --stdout")
+			}
+
+			generators, err := newGenerators(cmd)
 			if err != nil {
 				return err
 			}
 
-			catalog, err := sqlc.LoadCatalog(cmd.String("catalog-file"))
-			if err != nil {
+			stdout := &sqlc.StdoutWriter{Writer: cmd.Root().Writer, Table: cmd.String("table")}
+			for _, generator := range generators {
+				switch {
+				case cmd.Bool("dry-run"):
+					generator.Writer = &sqlc.DryRunWriter{Writer: cmd.Root().Writer}
+				case cmd.Bool("stdout"):
+					generator.Writer = stdout
+				}
+			}
+
+			if err := generators.Generate(); err != nil {
 				return err
 			}
 
-			generator := &sqlc.Generator{
-				Config:  config,
-				Catalog: catalog,
+			// The table is looked up across the files of every generator
+			if cmd.Bool("stdout") {
+				return stdout.Close()
 			}
 
-			return generator.Generate()
+			return nil
 		},
 	}
 
@@ -60,3 +162,54 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newGenerators loads the configuration and the catalog according to the
+// global flags. The atlas source shares one catalog across every sql block,
+// while the ddl source parses the schema of each sql block on its own.
+func newGenerators(cmd *cli.Command) (sqlc.Generators, error) {
+	config, err := sqlc.LoadConfig(cmd.String("config-file"))
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.String("catalog-source") == "atlas" {
+		catalog, err := sqlc.LoadCatalog(cmd.String("catalog-file"))
+		if err != nil {
+			return nil, err
+		}
+
+		return sqlc.Generators{{
+			Config:      config,
+			Catalog:     catalog,
+			TemplateDir: cmd.String("template-dir"),
+			NoPrune:     cmd.Bool("no-prune"),
+			Strict:      cmd.Bool("strict"),
+		}}, nil
+	}
+
+	var generators sqlc.Generators
+	for _, block := range config.SQL {
+		dialect, err := sqlc.GetDialect(block.Engine)
+		if err != nil {
+			return nil, err
+		}
+
+		catalog, err := sqlc.LoadCatalogDDL(block.Engine, dialect.Schema, block.Schema)
+		if err != nil {
+			return nil, err
+		}
+
+		generators = append(generators, &sqlc.Generator{
+			Config: &sqlc.Config{
+				Version: config.Version,
+				SQL:     []sqlc.SQL{block},
+			},
+			Catalog:     catalog,
+			TemplateDir: cmd.String("template-dir"),
+			NoPrune:     cmd.Bool("no-prune"),
+			Strict:      cmd.Bool("strict"),
+		})
+	}
+
+	return generators, nil
+}
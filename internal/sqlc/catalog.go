@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"slices"
 	"strings"
 )
@@ -48,6 +49,87 @@ func (x *Catalog) GetTable(name string) *Table {
 	return nil
 }
 
+// GetSchemaTable retrieves a table by schema and name from the catalog.
+func (x *Catalog) GetSchemaTable(schema, name string) *Table {
+	for i := range x.Schemas {
+		if x.Schemas[i].Name != schema {
+			continue
+		}
+		for j := range x.Schemas[i].Tables {
+			if x.Schemas[i].Tables[j].Name == name {
+				return &x.Schemas[i].Tables[j]
+			}
+		}
+	}
+	return nil
+}
+
+// GetReferencedTable retrieves the table referenced by a foreign key of a
+// table in the given schema, along with the schema of the referenced table.
+// An unqualified reference points into the given schema, or else to the only
+// table of that name, since Atlas catalogs never qualify references.
+func (x *Catalog) GetReferencedTable(schema string, fk ForeignKey) (string, *Table, error) {
+	name := fk.References.Table
+	if fk.References.Schema != "" {
+		schema = fk.References.Schema
+	}
+
+	if table := x.GetSchemaTable(schema, name); table != nil {
+		return schema, table, nil
+	}
+
+	if fk.References.Schema == "" {
+		var schemas []string
+		for _, item := range x.Schemas {
+			if x.GetSchemaTable(item.Name, name) != nil {
+				schemas = append(schemas, item.Name)
+			}
+		}
+
+		switch len(schemas) {
+		case 1:
+			return schemas[0], x.GetSchemaTable(schemas[0], name), nil
+		case 0:
+		default:
+			return "", nil, fmt.Errorf("foreign key %s references table %q, which exists in schemas %s", fk.Name, name, strings.Join(schemas, ", "))
+		}
+	}
+
+	return "", nil, fmt.Errorf("foreign key %s references table %q, which does not exist", fk.Name, name)
+}
+
+// MergeKeys copies the primary key, indexes and foreign keys of every table
+// in source onto the table with the same schema and name in the catalog,
+// along with the default, identity and generated metadata of its columns.
+func (x *Catalog) MergeKeys(source *Catalog) {
+	for i := range x.Schemas {
+		for _, schema := range source.Schemas {
+			if schema.Name != x.Schemas[i].Name {
+				continue
+			}
+
+			for j := range x.Schemas[i].Tables {
+				table := &x.Schemas[i].Tables[j]
+				for _, item := range schema.Tables {
+					if item.Name == table.Name {
+						table.PrimaryKey = item.PrimaryKey
+						table.Indexes = item.Indexes
+						table.ForeignKeys = item.ForeignKeys
+
+						for k := range table.Columns {
+							if column := item.GetColumn(table.Columns[k].Name); column != nil {
+								table.Columns[k].Default = column.Default
+								table.Columns[k].Identity = column.Identity
+								table.Columns[k].Generated = column.Generated
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
 // Schema represents a database schema containing tables and other database objects.
 type Schema struct {
 	Name   string  `json:"name"`
@@ -119,23 +201,89 @@ func (x *Table) GetUniqueKeys() []*Index {
 	return keys
 }
 
+// GetInsertColumns retrieves all columns from the table that an INSERT can set.
+// Identity and generated columns are left out since the database assigns them.
+func (x *Table) GetInsertColumns() []Column {
+	var columns []Column
+
+	for _, column := range x.Columns {
+		if !column.Identity && column.Generated == "" {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns
+}
+
+// GetUpsertInsertColumns retrieves the columns an upsert on the given key inserts. These are the
+// columns an INSERT can set plus the identity columns of the key, which the upsert must bind to
+// find the existing row.
+func (x *Table) GetUpsertInsertColumns(key *Index) []Column {
+	var columns []Column
+
+	for _, column := range x.Columns {
+		if column.Generated != "" || column.Identity && (key == nil || !key.HasColumn(column.Name)) {
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
 // GetNonPrimaryKeyColumns retrieves all columns from the table except the primary key columns.
 // This is useful for generating UPDATE statements where primary keys should not be modified.
 func (x *Table) GetNonPrimaryKeyColumns() []Column {
+	return x.GetNonKeyColumns(x.PrimaryKey)
+}
+
+// GetNonKeyColumns retrieves all columns from the table except the columns of the given key.
+// This is useful for generating upserts where the conflict target should not be modified.
+func (x *Table) GetNonKeyColumns(key *Index) []Column {
 	var columns []Column
 
-	// Get primary key column names for exclusion
-	var pkColumns map[string]bool
-	if x.PrimaryKey != nil {
-		pkColumns = make(map[string]bool)
-		for _, part := range x.PrimaryKey.Parts {
-			pkColumns[part.Column] = true
+	// Get key column names for exclusion
+	var keyColumns map[string]bool
+	if key != nil {
+		keyColumns = make(map[string]bool)
+		for _, part := range key.Parts {
+			keyColumns[part.Column] = true
 		}
 	}
 
-	// Add all non-primary key columns
+	// Add all non-key columns
 	for _, column := range x.Columns {
-		if pkColumns == nil || !pkColumns[column.Name] {
+		if keyColumns == nil || !keyColumns[column.Name] {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns
+}
+
+// GetUpsertColumns retrieves the columns an upsert on the given key updates when the row exists.
+// The key, the primary key and identity columns are left out so that the row keeps its identity
+// and the rows referencing it stay attached.
+func (x *Table) GetUpsertColumns(key *Index) []Column {
+	var columns []Column
+
+	for _, column := range x.GetNonKeyColumns(key) {
+		if column.Identity || x.PrimaryKey != nil && x.PrimaryKey.HasColumn(column.Name) {
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// GetSelectColumns retrieves the columns the queries select and return, leaving out the
+// excluded ones. Excluded columns are still written (see ColumnOptions).
+func (x *Table) GetSelectColumns(exclude map[string]bool) []Column {
+	var columns []Column
+
+	for _, column := range x.Columns {
+		if !exclude[column.Name] {
 			columns = append(columns, column)
 		}
 	}
@@ -165,25 +313,47 @@ func (x *Table) IsForeignKeyIndex(index *Index) bool {
 }
 
 // Column represents a table column with its name, data type, and nullability.
+// Default holds the default value expression, Identity reports whether the
+// database assigns the value (identity, serial or auto-increment columns) and
+// Generated holds the expression of a generated (computed) column. Atlas
+// catalogs carry none of the three, which are read from the schema DDL (see
+// LoadCatalogDDL) unless the catalog file sets them.
 type Column struct {
-	Name string `json:"name"`
-	Type string `json:"type,omitempty"`
-	Null bool   `json:"null,omitempty"`
+	Name      string `json:"name"`
+	Type      string `json:"type,omitempty"`
+	Null      bool   `json:"null,omitempty"`
+	Default   string `json:"default,omitempty"`
+	Identity  bool   `json:"identity,omitempty"`
+	Generated string `json:"generated,omitempty"`
 	// Inherit common attributes
 	Attributes
 }
 
 // ColumnRef represents a reference to a specific column within a table.
+// Schema qualifies the table when it lives outside the current schema. The
+// Dialect quotes reserved and mixed-case names, or every name when Quoted is
+// set.
 type ColumnRef struct {
-	Name  string
-	Table *Table
+	Name    string
+	Schema  string
+	Table   *Table
+	Dialect *Dialect
+	Quoted  bool
 }
 
 // String returns the string representation of the ColumnRef for use in SQL queries.
 func (x *ColumnRef) String() string {
 	column := x.Table.GetColumn(x.Name)
 	// Prepare the column reference in "table.column" format
-	return fmt.Sprintf("%s.%s", x.Table.Name, column.Name)
+	parts := []string{x.Table.Name, column.Name}
+	if x.Schema != "" {
+		// Prepare the column reference in "schema.table.column" format
+		parts = append([]string{x.Schema}, parts...)
+	}
+	if x.Quoted {
+		return x.Dialect.Quote(parts...)
+	}
+	return x.Dialect.Ident(parts...)
 }
 
 // Index represents a database index on one or more columns or expressions.
@@ -191,6 +361,15 @@ type Index struct {
 	Name   string      `json:"name,omitempty"`
 	Unique bool        `json:"unique,omitempty"`
 	Parts  []IndexPart `json:"parts,omitempty"`
+	// Where is the predicate of a partial index, if any.
+	Where string `json:"where,omitempty"`
+}
+
+// HasColumn checks if the index contains the given column.
+func (x *Index) HasColumn(name string) bool {
+	return slices.ContainsFunc(x.Parts, func(x IndexPart) bool {
+		return x.Column == name
+	})
 }
 
 // HasExpr checks if the index contains any expression-based parts.
@@ -213,6 +392,9 @@ type ForeignKey struct {
 	Name       string   `json:"name"`
 	Columns    []string `json:"columns,omitempty"`
 	References struct {
+		// Schema is the schema of the referenced table. An empty schema
+		// refers to the schema of the referencing table.
+		Schema  string   `json:"schema,omitempty"`
 		Table   string   `json:"table"`
 		Columns []string `json:"columns,omitempty"`
 	} `json:"references"`
@@ -222,11 +404,12 @@ type ForeignKey struct {
 type ArgumentCondition struct {
 	Column   *Column
 	Argument *Argument
+	Dialect  *Dialect
 }
 
 // String returns the string representation of the Condition for use in SQL queries.
 func (x *ArgumentCondition) String() string {
-	return fmt.Sprintf("%s = %v", x.Column.Name, x.Argument)
+	return fmt.Sprintf("%s = %v", x.Dialect.Ident(x.Column.Name), x.Argument)
 }
 
 type ColumnCondition struct {
@@ -239,10 +422,78 @@ func (x *ColumnCondition) String() string {
 	return fmt.Sprintf("%s = %s", x.Left.String(), x.Right.String())
 }
 
+// CursorCondition represents a keyset pagination condition that selects the
+// rows following a cursor in the order of an index. The cursor is bound as one
+// after_<column> argument per index part.
+type CursorCondition struct {
+	Index   *Index
+	Dialect *Dialect
+}
+
+// String returns the string representation of the CursorCondition for use in SQL queries.
+func (x *CursorCondition) String() string {
+	var (
+		columns   []string
+		arguments []string
+	)
+
+	desc := x.Index.Parts[0].Desc
+	mixed := false
+
+	for _, part := range x.Index.Parts {
+		columns = append(columns, x.Dialect.Ident(part.Column))
+		arguments = append(arguments, fmt.Sprintf("sqlc.arg(%s)", x.Dialect.Param("after_"+part.Column)))
+		mixed = mixed || part.Desc != desc
+	}
+
+	operator := func(desc bool) string {
+		if desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	if len(columns) == 1 {
+		return fmt.Sprintf("%s %s %s", columns[0], operator(desc), arguments[0])
+	}
+
+	if !mixed {
+		// All parts share a direction, so a row-value comparison does it
+		return fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), operator(desc), strings.Join(arguments, ", "))
+	}
+
+	// Mixed directions need one branch per part: equal on the preceding
+	// parts and past the cursor on the current one
+	var branches []string
+	for i, part := range x.Index.Parts {
+		condition := &CompositeCondition{Operator: "AND"}
+		for j := range i {
+			condition.Conditions = append(condition.Conditions,
+				literal(fmt.Sprintf("%s = %s", columns[j], arguments[j])))
+		}
+		condition.Conditions = append(condition.Conditions,
+			literal(fmt.Sprintf("%s %s %s", columns[i], operator(part.Desc), arguments[i])))
+		branches = append(branches, "("+condition.String()+")")
+	}
+
+	return "(" + strings.Join(branches, " OR ") + ")"
+}
+
+// literal is a raw SQL expression used as a condition.
+type literal string
+
+// String returns the SQL expression.
+func (x literal) String() string {
+	return string(x)
+}
+
 // CompositeCondition represents a combination of multiple conditions using a logical operator (e.g., AND, OR).
+// The Dialect is passed on to the column conditions it adds.
 type CompositeCondition struct {
 	Operator   string
 	Conditions []fmt.Stringer
+	Dialect    *Dialect
 }
 
 // AddColumn adds a new condition for the specified column to the CompositeCondition.
@@ -251,8 +502,10 @@ func (x *CompositeCondition) AddColumn(column *Column) {
 		&ArgumentCondition{
 			Column: column,
 			Argument: &Argument{
-				Column: column,
+				Column:  column,
+				Dialect: x.Dialect,
 			},
+			Dialect: x.Dialect,
 		},
 	)
 }
@@ -286,16 +539,42 @@ func (x *CompositeCondition) String() string {
 
 // Argument represents SQL argument corresponding to a column.
 type Argument struct {
-	Column *Column
+	Column  *Column
+	Dialect *Dialect
 }
 
 // String returns the string representation of the Argument for use in SQL queries.
 func (x *Argument) String() string {
+	name := x.Dialect.Param(x.Column.Name)
 	// Prepare the argument string based on nullability
 	if x.Column.Null {
-		return fmt.Sprintf("sqlc.narg(%s)", x.Column.Name)
+		return fmt.Sprintf("sqlc.narg(%s)", name)
+	}
+	return fmt.Sprintf("sqlc.arg(%s)", name)
+}
+
+var (
+	// acronym matches the end of an upper-case run followed by a word, e.g.
+	// PS in HTTPStatus.
+	acronym = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
+	// camel matches a lower-case letter or digit followed by an upper-case
+	// letter, e.g. dA in createdAt.
+	camel = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	// separator matches runs of characters not allowed in argument names.
+	separator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// paramName converts a column name into a snake_case argument name.
+func paramName(name string) string {
+	name = acronym.ReplaceAllString(name, "${1}_${2}")
+	name = camel.ReplaceAllString(name, "${1}_${2}")
+	name = separator.ReplaceAllString(strings.ToLower(name), "_")
+	name = strings.Trim(name, "_")
+	// Argument names cannot start with a digit
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "col_" + name
 	}
-	return fmt.Sprintf("sqlc.arg(%s)", x.Column.Name)
+	return name
 }
 
 // Attributes represents common attributes that can be applied to schemas, tables, and columns.
@@ -92,6 +92,22 @@ var _ = Describe("Catalog", func() {
 			Expect(exprIndex.Parts[0].Column).To(BeEmpty())
 		})
 
+		It("reads the default, identity and generated metadata of the columns", func() {
+			catalog, err := sqlc.LoadCatalog("./catalog_columns_test.json")
+			Expect(err).NotTo(HaveOccurred())
+
+			events := catalog.GetTable("events")
+			Expect(events.GetColumn("id").Identity).To(BeTrue())
+			Expect(events.GetColumn("payload").Default).To(Equal("'{}'"))
+			Expect(events.GetColumn("size").Generated).To(Equal("length(payload)"))
+			Expect(events.GetInsertColumns()).To(HaveLen(1))
+
+			// Atlas catalogs have none of them
+			catalog, err = sqlc.LoadCatalog("./catalog_test.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.GetTable("users").GetInsertColumns()).To(HaveLen(3))
+		})
+
 		When("the file does not exist", func() {
 			It("returns an error", func() {
 				catalog, err := sqlc.LoadCatalog("./catalog_test.yaml")
@@ -109,6 +125,64 @@ var _ = Describe("Catalog", func() {
 		})
 	})
 
+	Describe("GetSchemaTable", func() {
+		It("finds a table only in the given schema", func() {
+			catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.GetSchemaTable("public", "users")).NotTo(BeNil())
+			Expect(catalog.GetSchemaTable("auth", "users")).To(BeNil())
+		})
+	})
+
+	Describe("GetReferencedTable", func() {
+		var (
+			catalog *sqlc.Catalog
+			fk      sqlc.ForeignKey
+		)
+
+		BeforeEach(func() {
+			catalog = &sqlc.Catalog{
+				Schemas: []sqlc.Schema{
+					{Name: "public", Tables: []sqlc.Table{{Name: "posts"}, {Name: "tags"}}},
+					{Name: "billing", Tables: []sqlc.Table{{Name: "accounts"}, {Name: "tags"}}},
+				},
+			}
+			fk = sqlc.ForeignKey{Name: "posts_fkey"}
+		})
+
+		It("looks up an unqualified reference in the given schema first", func() {
+			fk.References.Table = "tags"
+			schema, table, err := catalog.GetReferencedTable("public", fk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schema).To(Equal("public"))
+			Expect(table).To(BeIdenticalTo(&catalog.Schemas[0].Tables[1]))
+		})
+
+		It("falls back to the only table with the referenced name", func() {
+			fk.References.Table = "accounts"
+			schema, _, err := catalog.GetReferencedTable("public", fk)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schema).To(Equal("billing"))
+		})
+
+		When("the reference is ambiguous", func() {
+			It("returns an error", func() {
+				fk.References.Table = "tags"
+				_, _, err := catalog.GetReferencedTable("audit", fk)
+				Expect(err).To(MatchError(`foreign key posts_fkey references table "tags", which exists in schemas public, billing`))
+			})
+		})
+
+		When("the referenced table does not exist", func() {
+			It("returns an error", func() {
+				fk.References.Schema = "billing"
+				fk.References.Table = "posts"
+				_, _, err := catalog.GetReferencedTable("public", fk)
+				Expect(err).To(MatchError(`foreign key posts_fkey references table "posts", which does not exist`))
+			})
+		})
+	})
+
 	Describe("Table methods", func() {
 		var catalog *sqlc.Catalog
 		var usersTable *sqlc.Table
@@ -236,6 +310,43 @@ var _ = Describe("Catalog", func() {
 		})
 	})
 
+	Describe("CursorCondition", func() {
+		It("compares a single column", func() {
+			condition := &sqlc.CursorCondition{
+				Index: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}},
+			}
+
+			Expect(condition.String()).To(Equal("id > sqlc.arg(after_id)"))
+		})
+
+		It("compares a composite key as a row value", func() {
+			condition := &sqlc.CursorCondition{
+				Index: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "tenant_id"}, {Column: "id"}}},
+			}
+
+			Expect(condition.String()).To(Equal("(tenant_id, id) > (sqlc.arg(after_tenant_id), sqlc.arg(after_id))"))
+		})
+
+		It("follows a descending key", func() {
+			condition := &sqlc.CursorCondition{
+				Index: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "created_at", Desc: true}, {Column: "id", Desc: true}}},
+			}
+
+			Expect(condition.String()).To(Equal("(created_at, id) < (sqlc.arg(after_created_at), sqlc.arg(after_id))"))
+		})
+
+		It("expands a key with mixed directions", func() {
+			condition := &sqlc.CursorCondition{
+				Index: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "created_at", Desc: true}, {Column: "id"}}},
+			}
+
+			Expect(condition.String()).To(Equal(
+				"((created_at < sqlc.arg(after_created_at)) OR " +
+					"(created_at = sqlc.arg(after_created_at) AND id > sqlc.arg(after_id)))",
+			))
+		})
+	})
+
 	Describe("Argument", func() {
 		Context("when column is nullable", func() {
 			It("uses sqlc.narg", func() {
@@ -268,6 +379,28 @@ var _ = Describe("Catalog", func() {
 				Expect(arg.String()).To(Equal("sqlc.arg(id)"))
 			})
 		})
+
+		Context("when column is not a plain name", func() {
+			It("uses a snake_case argument name", func() {
+				arg := &sqlc.Argument{
+					Column: &sqlc.Column{Name: "createdAt"},
+				}
+
+				Expect(arg.String()).To(Equal("sqlc.arg(created_at)"))
+			})
+
+			It("quotes a reserved argument name", func() {
+				dialect, err := sqlc.GetDialect("postgresql")
+				Expect(err).NotTo(HaveOccurred())
+
+				arg := &sqlc.Argument{
+					Column:  &sqlc.Column{Name: "Order"},
+					Dialect: dialect,
+				}
+
+				Expect(arg.String()).To(Equal(`sqlc.arg("order")`))
+			})
+		})
 	})
 
 	Describe("CompositeCondition", func() {
@@ -347,6 +480,139 @@ var _ = Describe("Catalog", func() {
 			})
 		})
 
+		Describe("ColumnRef", func() {
+			table := &sqlc.Table{Name: "accounts", Columns: []sqlc.Column{{Name: "id"}}}
+
+			It("qualifies the column with the schema", func() {
+				ref := &sqlc.ColumnRef{Name: "id", Schema: "auth", Table: table}
+				Expect(ref.String()).To(Equal("auth.accounts.id"))
+			})
+
+			It("quotes every part when quoted is set", func() {
+				dialect, err := sqlc.GetDialect("postgresql")
+				Expect(err).NotTo(HaveOccurred())
+
+				ref := &sqlc.ColumnRef{Name: "id", Schema: "auth", Table: table, Dialect: dialect, Quoted: true}
+				Expect(ref.String()).To(Equal(`"auth"."accounts"."id"`))
+			})
+
+			It("quotes reserved words only", func() {
+				dialect, err := sqlc.GetDialect("postgresql")
+				Expect(err).NotTo(HaveOccurred())
+
+				ref := &sqlc.ColumnRef{Name: "user", Table: &sqlc.Table{Name: "order", Columns: []sqlc.Column{{Name: "user"}}}, Dialect: dialect}
+				Expect(ref.String()).To(Equal(`"order"."user"`))
+			})
+		})
+
+		Describe("GetInsertColumns", func() {
+			It("excludes identity and generated columns", func() {
+				table := &sqlc.Table{
+					Name: "events",
+					Columns: []sqlc.Column{
+						{Name: "id", Identity: true},
+						{Name: "payload", Default: "'{}'"},
+						{Name: "size", Generated: "length(payload)"},
+					},
+				}
+
+				columns := table.GetInsertColumns()
+				Expect(columns).To(HaveLen(1))
+				Expect(columns[0].Name).To(Equal("payload"))
+
+			})
+		})
+
+		Describe("GetUpsertInsertColumns", func() {
+			var table *sqlc.Table
+
+			BeforeEach(func() {
+				table = &sqlc.Table{
+					Name: "events",
+					Columns: []sqlc.Column{
+						{Name: "id", Identity: true},
+						{Name: "payload", Default: "'{}'"},
+						{Name: "size", Generated: "length(payload)"},
+					},
+				}
+			})
+
+			It("includes the identity columns of the key", func() {
+				columns := table.GetUpsertInsertColumns(&sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}})
+				Expect(columns).To(HaveLen(2))
+				Expect(columns[0].Name).To(Equal("id"))
+				Expect(columns[1].Name).To(Equal("payload"))
+			})
+
+			It("excludes the identity columns outside the key", func() {
+				columns := table.GetUpsertInsertColumns(&sqlc.Index{Parts: []sqlc.IndexPart{{Column: "payload"}}})
+				Expect(columns).To(HaveLen(1))
+				Expect(columns[0].Name).To(Equal("payload"))
+			})
+		})
+
+		Describe("GetNonKeyColumns", func() {
+			It("excludes the columns of the given key", func() {
+				catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+				Expect(err).NotTo(HaveOccurred())
+				usersTable := &catalog.Schemas[0].Tables[0]
+				columns := usersTable.GetNonKeyColumns(&usersTable.Indexes[0])
+
+				columnNames := make([]string, len(columns))
+				for i, col := range columns {
+					columnNames[i] = col.Name
+				}
+
+				Expect(columnNames).To(Equal([]string{"id", "name"}))
+			})
+
+			It("returns all columns when the key is nil", func() {
+				table := &sqlc.Table{
+					Name:    "test_table",
+					Columns: []sqlc.Column{{Name: "id"}, {Name: "name"}},
+				}
+
+				Expect(table.GetNonKeyColumns(nil)).To(HaveLen(2))
+			})
+		})
+
+		Describe("GetUpsertColumns", func() {
+			It("excludes the key, primary key and identity columns", func() {
+				table := &sqlc.Table{
+					Name: "accounts",
+					Columns: []sqlc.Column{
+						{Name: "id"},
+						{Name: "number", Identity: true},
+						{Name: "email"},
+						{Name: "name"},
+					},
+					PrimaryKey: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}},
+				}
+				key := &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "email"}}}
+
+				columns := table.GetUpsertColumns(key)
+				Expect(columns).To(HaveLen(1))
+				Expect(columns[0].Name).To(Equal("name"))
+			})
+		})
+
+		Describe("GetSelectColumns", func() {
+			It("leaves out the excluded columns", func() {
+				catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+				Expect(err).NotTo(HaveOccurred())
+				usersTable := &catalog.Schemas[0].Tables[0]
+				columns := usersTable.GetSelectColumns(map[string]bool{"email": true})
+
+				columnNames := make([]string, len(columns))
+				for i, col := range columns {
+					columnNames[i] = col.Name
+				}
+
+				Expect(columnNames).To(Equal([]string{"id", "name"}))
+				Expect(usersTable.GetSelectColumns(nil)).To(HaveLen(3))
+			})
+		})
+
 		Describe("GetNonPrimaryKeyColumns", func() {
 			It("excludes primary key columns", func() {
 				catalog, err := sqlc.LoadCatalog("./catalog_test.json")
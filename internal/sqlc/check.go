@@ -0,0 +1,248 @@
+package sqlc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DiffStatus describes how a file on disk differs from the rendered one.
+type DiffStatus string
+
+const (
+	// DiffModified marks a file whose content differs from the rendered one.
+	DiffModified DiffStatus = "modified"
+	// DiffMissing marks a rendered file that does not exist on disk.
+	DiffMissing DiffStatus = "missing"
+	// DiffExtra marks a generated file on disk that is no longer rendered.
+	DiffExtra DiffStatus = "extra"
+)
+
+// Diff represents a file that is out of date.
+type Diff struct {
+	// Path is the file path, relative to the working directory.
+	Path string
+	// Status tells whether the file is modified, missing or extra.
+	Status DiffStatus
+	// Patch is the unified diff from the file on disk to the rendered file.
+	// It is only set for modified files.
+	Patch string
+}
+
+// Check renders the queries and compares them with the files on disk without
+// modifying anything. It returns the files that are out of date, sorted by
+// path. Extra files are reported unless NoPrune is set.
+func (x *Generator) Check() ([]*Diff, error) {
+	return Generators{x}.Check()
+}
+
+// Check compares the files of every generator with the files on disk (see
+// Generator.Check). Extra files are the ones Generate would prune.
+func (x Generators) Check() ([]*Diff, error) {
+	var (
+		diffs []*Diff
+		files []*File
+	)
+	for _, generator := range x {
+		rendered, err := generator.Render()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range rendered {
+			data, err := os.ReadFile(file.Path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				diffs = append(diffs, &Diff{Path: file.Path, Status: DiffMissing})
+			case err != nil:
+				return nil, err
+			case !bytes.Equal(data, file.Content):
+				diffs = append(diffs, &Diff{
+					Path:   file.Path,
+					Status: DiffModified,
+					Patch:  unifiedDiff(file.Path, data, file.Content),
+				})
+			}
+		}
+
+		files = append(files, rendered...)
+	}
+
+	extra := make(map[string]bool)
+	for _, generator := range x {
+		if generator.NoPrune {
+			continue
+		}
+
+		stale, err := generator.stale(files)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range stale {
+			if !extra[path] {
+				diffs = append(diffs, &Diff{Path: path, Status: DiffExtra})
+				extra[path] = true
+			}
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b *Diff) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return diffs, nil
+}
+
+// diffContext is the number of unchanged lines around each hunk.
+const diffContext = 3
+
+// edit is a single line of an edit script.
+type edit struct {
+	// Op is ' ' for an unchanged line, '-' for a deleted line and '+' for an
+	// inserted line.
+	Op byte
+	// Line is the line without its trailing newline.
+	Line string
+}
+
+// maxDiffCells bounds the size of the longest common subsequence table of
+// unifiedDiff, about 8 MB. Single-file layouts of large schemas produce files
+// of tens of thousands of lines, too many to compare line by line.
+const maxDiffCells = 1 << 20
+
+// unifiedDiff returns the unified diff that turns before into after. The
+// lines the files start and end with in common are set aside, and the rest
+// is compared with a plain longest common subsequence. When the rest is too
+// large for that, only the fact that the files differ is reported.
+func unifiedDiff(path string, before, after []byte) string {
+	a, b := splitLines(before), splitLines(after)
+
+	// Set the common prefix and suffix aside
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(ma)+1)*(len(mb)+1) > maxDiffCells {
+		return fmt.Sprintf("Files a/%s and b/%s differ\n", filepath.ToSlash(path), filepath.ToSlash(path))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of ma[i:] and mb[j:]
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{Op: ' ', Line: line})
+	}
+	for i, j := 0, 0; i < len(ma) || j < len(mb); {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			edits = append(edits, edit{Op: ' ', Line: ma[i]})
+			i, j = i+1, j+1
+		case i < len(ma) && (j == len(mb) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{Op: '-', Line: ma[i]})
+			i++
+		default:
+			edits = append(edits, edit{Op: '+', Line: mb[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{Op: ' ', Line: line})
+	}
+
+	var buffer strings.Builder
+	fmt.Fprintf(&buffer, "--- a/%s\n+++ b/%s\n", filepath.ToSlash(path), filepath.ToSlash(path))
+
+	// line numbers of a and b before edits[k]
+	aline, bline := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for k, e := range edits {
+		aline[k+1], bline[k+1] = aline[k], bline[k]
+		if e.Op != '+' {
+			aline[k+1]++
+		}
+		if e.Op != '-' {
+			bline[k+1]++
+		}
+	}
+
+	for k := 0; k < len(edits); {
+		if edits[k].Op == ' ' {
+			k++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough to share context
+		start, end := max(k-diffContext, 0), k
+		for end < len(edits) {
+			next := end
+			for next < len(edits) && edits[next].Op != ' ' {
+				next++
+			}
+			end = next
+			gap := next
+			for gap < len(edits) && edits[gap].Op == ' ' {
+				gap++
+			}
+			if gap == len(edits) || gap-next > 2*diffContext {
+				break
+			}
+			end = gap
+		}
+		end = min(end+diffContext, len(edits))
+
+		acount, bcount := aline[end]-aline[start], bline[end]-bline[start]
+		fmt.Fprintf(&buffer, "@@ -%s +%s @@\n", hunkRange(aline[start], acount), hunkRange(bline[start], bcount))
+		for _, e := range edits[start:end] {
+			buffer.WriteByte(e.Op)
+			buffer.WriteString(e.Line)
+			buffer.WriteByte('\n')
+		}
+
+		k = end
+	}
+
+	return buffer.String()
+}
+
+// hunkRange formats the range of a hunk, where start is the zero-based line
+// the hunk starts at.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range refers to the line before it
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits data into lines without their trailing newlines.
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
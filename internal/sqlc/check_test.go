@@ -0,0 +1,144 @@
+package sqlc_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Check", func() {
+	var (
+		dir       string
+		out       string
+		generator *sqlc.Generator
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "sqlc-gen-check-*")
+		Expect(err).NotTo(HaveOccurred())
+		out = filepath.Join(dir, "generated")
+
+		catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		generator = &sqlc.Generator{
+			Catalog: catalog,
+			Config: &sqlc.Config{
+				Version: "2",
+				SQL: []sqlc.SQL{
+					{
+						Engine:  "postgresql",
+						Queries: dir,
+						Codegen: []sqlc.Codegen{{Plugin: "gen-queries", Out: out}},
+					},
+				},
+			},
+		}
+
+		Expect(generator.Generate()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("reports nothing when the files are up to date", func() {
+		Expect(generator.Check()).To(BeEmpty())
+	})
+
+	It("reports a modified file with a unified diff", func() {
+		path := filepath.Join(out, "users.sql")
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		stale := bytes.Replace(data, []byte("SELECT"), []byte("SELECT DISTINCT"), 1)
+		Expect(os.WriteFile(path, stale, 0o666)).To(Succeed())
+
+		diffs, err := generator.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diffs).To(HaveLen(1))
+		Expect(diffs[0].Path).To(Equal(path))
+		Expect(diffs[0].Status).To(Equal(sqlc.DiffModified))
+		Expect(diffs[0].Patch).To(HavePrefix("--- a/" + filepath.ToSlash(path) + "\n+++ b/" + filepath.ToSlash(path) + "\n@@ -"))
+		Expect(diffs[0].Patch).To(ContainSubstring("\n-SELECT DISTINCT\n+SELECT\n"))
+
+		// The files on disk are left alone
+		Expect(os.ReadFile(path)).To(Equal(stale))
+	})
+
+	It("reports a large modified file without a diff", func() {
+		path := filepath.Join(out, "users.sql")
+		stale := sqlc.Header + strings.Repeat("-- name: Stale :one\n", 20000)
+		Expect(os.WriteFile(path, []byte(stale), 0o666)).To(Succeed())
+
+		diffs, err := generator.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diffs).To(HaveLen(1))
+		Expect(diffs[0].Status).To(Equal(sqlc.DiffModified))
+		Expect(diffs[0].Patch).To(Equal("Files a/" + filepath.ToSlash(path) + " and b/" + filepath.ToSlash(path) + " differ\n"))
+	})
+
+	It("reports no extra files of the other generators sharing the directory", func() {
+		block := func(table string) *sqlc.Generator {
+			config := generator.Config.SQL[0]
+			config.Codegen = []sqlc.Codegen{
+				{
+					Plugin:  "gen-queries",
+					Out:     out,
+					Options: sqlc.CodegenOptions{Tables: sqlc.TableOptions{Include: []string{table}}},
+				},
+			}
+			return &sqlc.Generator{
+				Catalog: generator.Catalog,
+				Config:  &sqlc.Config{Version: "2", SQL: []sqlc.SQL{config}},
+			}
+		}
+
+		Expect(sqlc.Generators{block("users"), block("posts")}.Check()).To(BeEmpty())
+
+		diffs, err := sqlc.Generators{block("users")}.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diffs).To(HaveLen(1))
+		Expect(diffs[0].Path).To(Equal(filepath.Join(out, "posts.sql")))
+		Expect(diffs[0].Status).To(Equal(sqlc.DiffExtra))
+	})
+
+	It("reports missing and extra files", func() {
+		Expect(os.Remove(filepath.Join(out, "posts.sql"))).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(out, "comments.sql"), []byte(sqlc.Header+"-- name: ListComments :many\n"), 0o666)).To(Succeed())
+
+		diffs, err := generator.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diffs).To(HaveLen(2))
+		Expect(diffs[0].Path).To(Equal(filepath.Join(out, "comments.sql")))
+		Expect(diffs[0].Status).To(Equal(sqlc.DiffExtra))
+		Expect(diffs[1].Path).To(Equal(filepath.Join(out, "posts.sql")))
+		Expect(diffs[1].Status).To(Equal(sqlc.DiffMissing))
+
+		Expect(filepath.Join(out, "posts.sql")).NotTo(BeAnExistingFile())
+	})
+
+	It("ignores hand-written queries next to the generated ones", func() {
+		Expect(os.WriteFile(filepath.Join(out, "custom.sql"), []byte("-- name: Custom :one\n"), 0o666)).To(Succeed())
+
+		Expect(generator.Check()).To(BeEmpty())
+	})
+
+	It("reports every file as missing before the first generation", func() {
+		Expect(os.RemoveAll(out)).To(Succeed())
+
+		diffs, err := generator.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diffs).To(HaveLen(2))
+		for _, diff := range diffs {
+			Expect(diff.Status).To(Equal(sqlc.DiffMissing))
+		}
+	})
+})
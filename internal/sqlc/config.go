@@ -5,7 +5,10 @@
 package sqlc
 
 import (
+	"cmp"
 	"os"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -71,14 +74,38 @@ type Codegen struct {
 
 // CodegenOptions holds plugin-specific options for the gen-queries plugin.
 type CodegenOptions struct {
-	Queries QueryOptions `yaml:"queries,omitempty"`
-	Tables  TableOptions `yaml:"tables,omitempty"`
+	Queries    QueryOptions      `yaml:"queries,omitempty"`
+	Tables     TableOptions      `yaml:"tables,omitempty"`
+	SoftDelete SoftDeleteOptions `yaml:"soft_delete,omitempty"`
+	Timestamps TimestampOptions  `yaml:"timestamps,omitempty"`
+	// Layout selects how queries are split into files (see Layouts).
+	Layout string `yaml:"layout,omitempty"`
+	// Filename is a Go template for the file names, relative to the output
+	// directory (see FileName). It defaults to the pattern of the layout.
+	Filename string `yaml:"filename,omitempty"`
+	// SchemaPrefix selects when table names are prefixed with their schema
+	// to tell apart same-named tables (see SchemaPrefixes).
+	SchemaPrefix string `yaml:"schema_prefix,omitempty"`
+	// Qualify emits quoted, schema-qualified table names instead of a
+	// SET search_path statement.
+	Qualify bool `yaml:"qualify,omitempty"`
+	// Templates is the directory of the *.tmpl files that replace the
+	// built-in template or some of its blocks.
+	Templates string `yaml:"templates,omitempty"`
+	// Columns holds the columns left out of the results of, or never written
+	// by, the queries of every table.
+	Columns ColumnOptions `yaml:"columns,omitempty"`
+	// Naming holds the inflection rules of the query names.
+	Naming NamingOptions `yaml:"naming,omitempty"`
+	// Overrides holds the options of the tables matching each key, given by
+	// unqualified or schema-qualified table name or pattern (see Patterns).
+	Overrides map[string]TableOverride `yaml:"overrides,omitempty"`
 }
 
 // QueryOptions holds query-level filtering options for the gen-queries plugin.
 // Include adds opt-in queries on top of the default query set. Exclude removes
 // queries from what would otherwise be generated and always takes precedence
-// over Include and the defaults.
+// over Include and the defaults. Entries are names or patterns (see Patterns).
 type QueryOptions struct {
 	Include []string `yaml:"include,omitempty"`
 	Exclude []string `yaml:"exclude,omitempty"`
@@ -87,11 +114,72 @@ type QueryOptions struct {
 // TableOptions holds table-level filtering options for the gen-queries plugin.
 // Include is an allow-list: when non-empty, only the listed tables are
 // generated. Exclude is a deny-list that always takes precedence over Include.
+// Entries are names or patterns (see Patterns).
 type TableOptions struct {
 	Include []string `yaml:"include,omitempty"`
 	Exclude []string `yaml:"exclude,omitempty"`
 }
 
+// SoftDeleteOptions holds the soft-delete options for the gen-queries plugin.
+// Column names the nullable timestamp column that marks a row as deleted in
+// every table. Overrides replace the column per table (see TableOverride).
+type SoftDeleteOptions struct {
+	Column string `yaml:"column,omitempty"`
+}
+
+// TimestampOptions holds the timestamp column options for the gen-queries
+// plugin. Created lists the columns set to the current time on insert and
+// never updated afterwards. Updated lists the columns set to the current time
+// on insert and on every update.
+type TimestampOptions struct {
+	Created []string `yaml:"created,omitempty"`
+	Updated []string `yaml:"updated,omitempty"`
+}
+
+// ColumnOptions holds the column options for the gen-queries plugin. Exclude
+// lists the columns the queries never select or return: the queries of the
+// tables that have any select and return the remaining columns by name.
+// Excluded columns are still written, unless they are read-only as well.
+// Readonly lists the columns the queries read but never write, such as those
+// filled in by the database.
+type ColumnOptions struct {
+	Exclude  []string `yaml:"exclude,omitempty"`
+	Readonly []string `yaml:"readonly,omitempty"`
+}
+
+// NamingOptions holds the inflection rules the singular and plural forms of
+// the table names are derived with, on top of the built-in English rules.
+// Singular and Plural map a name suffix to its replacement, e.g. "ia" to
+// "ium" for singulars. Irregular maps singular words to their plural, e.g.
+// "person" to "people". Uncountable lists the words whose plural is the same,
+// e.g. "data". Every rule applies to the end of a name, so a rule for data
+// applies to user_data as well.
+type NamingOptions struct {
+	Singular    map[string]string `yaml:"singular,omitempty"`
+	Plural      map[string]string `yaml:"plural,omitempty"`
+	Irregular   map[string]string `yaml:"irregular,omitempty"`
+	Uncountable []string          `yaml:"uncountable,omitempty"`
+}
+
+// TableOverride holds the options of the tables matching an overrides key.
+// Lists are appended to those of the block, while SoftDelete, Name, Singular
+// and Plural replace the block values when set. An empty SoftDelete turns soft
+// delete off for the tables.
+type TableOverride struct {
+	Queries    QueryOptions     `yaml:"queries,omitempty"`
+	SoftDelete *string          `yaml:"soft_delete,omitempty"`
+	Timestamps TimestampOptions `yaml:"timestamps,omitempty"`
+	Columns    ColumnOptions    `yaml:"columns,omitempty"`
+	// Name replaces the name the queries and files of the tables are named
+	// after (see SchemaPrefix).
+	Name string `yaml:"name,omitempty"`
+	// Singular and Plural set the forms of the name the queries are named
+	// after, e.g. GetPerson and ListPeople, instead of inflecting it. A
+	// missing form is inflected from the other one.
+	Singular string `yaml:"singular,omitempty"`
+	Plural   string `yaml:"plural,omitempty"`
+}
+
 // GetOptions returns the CodegenOptions for the gen-queries plugin.
 // If no matching codegen entry is found, returns an empty CodegenOptions.
 func (s *SQL) GetOptions() CodegenOptions {
@@ -103,62 +191,175 @@ func (s *SQL) GetOptions() CodegenOptions {
 	return CodegenOptions{}
 }
 
-// GetQueryIncludeSet returns the set of opt-in query names to generate in
-// addition to the default query set.
-func (s *SQL) GetQueryIncludeSet() map[string]bool {
-	opts := s.GetOptions()
-	includeSet := make(map[string]bool, len(opts.Queries.Include))
-	for _, name := range opts.Queries.Include {
-		includeSet[name] = true
+// GetOut returns the directory the queries are written to: the `out` of the
+// gen-queries codegen entry when set, otherwise the queries path.
+func (s *SQL) GetOut() string {
+	for _, c := range s.Codegen {
+		if c.Plugin == PluginName && c.Out != "" {
+			return c.Out
+		}
 	}
-	return includeSet
+	return s.Queries
+}
+
+// GetQueryIncludeSet returns the set of opt-in query names and patterns to
+// generate in addition to the default query set.
+func (s *SQL) GetQueryIncludeSet() Patterns {
+	return newPatterns(s.GetOptions().Queries.Include)
+}
+
+// GetQueryExcludeSet returns the deny-list of query names and patterns to
+// skip. Excluded queries are never generated, even when they belong to the
+// default set.
+func (s *SQL) GetQueryExcludeSet() Patterns {
+	return newPatterns(s.GetOptions().Queries.Exclude)
+}
+
+// GetIncludeSet returns the allow-list of table names and patterns for query
+// generation. Entries may match unqualified table names or schema-qualified
+// names. An empty set means every table is included.
+func (s *SQL) GetIncludeSet() Patterns {
+	return newPatterns(s.GetOptions().Tables.Include)
+}
+
+// GetExcludeSet returns the deny-list of table names and patterns to skip
+// during query generation. Entries may match unqualified table names or
+// schema-qualified names.
+func (s *SQL) GetExcludeSet() Patterns {
+	return newPatterns(s.GetOptions().Tables.Exclude)
 }
 
-// GetQueryExcludeSet returns the deny-list of query names to skip. Excluded
-// queries are never generated, even when they belong to the default set.
-func (s *SQL) GetQueryExcludeSet() map[string]bool {
+// GetCreatedAtSet returns the set of column names that record when a row was
+// created.
+func (s *SQL) GetCreatedAtSet() map[string]bool {
 	opts := s.GetOptions()
-	excludeSet := make(map[string]bool, len(opts.Queries.Exclude))
-	for _, name := range opts.Queries.Exclude {
-		excludeSet[name] = true
+	createdSet := make(map[string]bool, len(opts.Timestamps.Created))
+	for _, name := range opts.Timestamps.Created {
+		createdSet[name] = true
 	}
-	return excludeSet
+	return createdSet
 }
 
-// GetIncludeSet returns the allow-list of table names for query generation.
-// Entries may be unqualified table names or schema-qualified names. An empty
-// set means every table is included.
-func (s *SQL) GetIncludeSet() map[string]bool {
+// GetUpdatedAtSet returns the set of column names that record when a row was
+// last updated.
+func (s *SQL) GetUpdatedAtSet() map[string]bool {
 	opts := s.GetOptions()
-	includeSet := make(map[string]bool, len(opts.Tables.Include))
-	for _, name := range opts.Tables.Include {
-		includeSet[name] = true
+	updatedSet := make(map[string]bool, len(opts.Timestamps.Updated))
+	for _, name := range opts.Timestamps.Updated {
+		updatedSet[name] = true
+	}
+	return updatedSet
+}
+
+// TableConfig holds the options of a table: those of the SQL block merged
+// with the overrides matching the table (see SQL.GetTableConfig).
+type TableConfig struct {
+	// Name replaces the name the table is generated under, when set.
+	Name string
+	// Singular and Plural replace the inflected forms of the name, when set.
+	Singular string
+	Plural   string
+	// QueryInclude and QueryExclude select the queries of the table.
+	QueryInclude Patterns
+	QueryExclude Patterns
+	// SoftDelete is the soft-delete column, or empty when soft delete is off.
+	SoftDelete string
+	// CreatedAt and UpdatedAt are the timestamp columns.
+	CreatedAt map[string]bool
+	UpdatedAt map[string]bool
+	// ExcludeColumns holds the columns left out of the selected and returned rows.
+	ExcludeColumns map[string]bool
+	// ReadonlyColumns holds the columns that are never written.
+	ReadonlyColumns map[string]bool
+}
+
+// GetOverrides returns the overrides matching the given table in the order
+// they apply: patterns first, sorted by key, then the unqualified name and
+// the schema-qualified name, so that the most specific key wins.
+func (s *SQL) GetOverrides(schema, table string) []TableOverride {
+	qualified := schema + "." + table
+	// rank orders the keys from the least to the most specific
+	rank := func(key string) int {
+		switch key {
+		case table:
+			return 1
+		case qualified:
+			return 2
+		}
+		return 0
+	}
+
+	overrides := s.GetOptions().Overrides
+
+	var keys []string
+	for key := range overrides {
+		if (Patterns{key: true}).Match(table, qualified) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), strings.Compare(a, b))
+	})
+
+	items := make([]TableOverride, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, overrides[key])
 	}
-	return includeSet
+	return items
 }
 
-// GetExcludeSet returns the deny-list of table names to skip during query
-// generation. Entries may be unqualified table names or schema-qualified names.
-func (s *SQL) GetExcludeSet() map[string]bool {
+// GetTableConfig resolves the options of the given table: the block options
+// with the matching overrides applied in order (see GetOverrides).
+func (s *SQL) GetTableConfig(schema, table string) *TableConfig {
 	opts := s.GetOptions()
-	excludeSet := make(map[string]bool, len(opts.Tables.Exclude))
-	for _, name := range opts.Tables.Exclude {
-		excludeSet[name] = true
+
+	var (
+		queryInclude    = slices.Clone(opts.Queries.Include)
+		queryExclude    = slices.Clone(opts.Queries.Exclude)
+		created         = slices.Clone(opts.Timestamps.Created)
+		updated         = slices.Clone(opts.Timestamps.Updated)
+		excludeColumns  = slices.Clone(opts.Columns.Exclude)
+		readonlyColumns = slices.Clone(opts.Columns.Readonly)
+	)
+
+	config := &TableConfig{SoftDelete: opts.SoftDelete.Column}
+	for _, override := range s.GetOverrides(schema, table) {
+		queryInclude = append(queryInclude, override.Queries.Include...)
+		queryExclude = append(queryExclude, override.Queries.Exclude...)
+		created = append(created, override.Timestamps.Created...)
+		updated = append(updated, override.Timestamps.Updated...)
+		excludeColumns = append(excludeColumns, override.Columns.Exclude...)
+		readonlyColumns = append(readonlyColumns, override.Columns.Readonly...)
+
+		if override.SoftDelete != nil {
+			config.SoftDelete = *override.SoftDelete
+		}
+		config.Name = cmp.Or(override.Name, config.Name)
+		config.Singular = cmp.Or(override.Singular, config.Singular)
+		config.Plural = cmp.Or(override.Plural, config.Plural)
 	}
-	return excludeSet
+
+	config.QueryInclude = newPatterns(queryInclude)
+	config.QueryExclude = newPatterns(queryExclude)
+	config.CreatedAt = newPatterns(created)
+	config.UpdatedAt = newPatterns(updated)
+	config.ExcludeColumns = newPatterns(excludeColumns)
+	config.ReadonlyColumns = newPatterns(readonlyColumns)
+	return config
 }
 
 // tableSelected reports whether a table should have query files generated.
 // Exclude always takes precedence over include; an empty include set matches
-// every table. Both sets are checked against the unqualified table name and
+// every table. Both sets are matched against the unqualified table name and
 // the schema-qualified name (schema.table).
-func tableSelected(includeSet, excludeSet map[string]bool, schema, table string) bool {
+func tableSelected(includeSet, excludeSet Patterns, schema, table string) bool {
 	qualified := schema + "." + table
-	if excludeSet[table] || excludeSet[qualified] {
+	if excludeSet.Match(table, qualified) {
 		return false
 	}
 	if len(includeSet) == 0 {
 		return true
 	}
-	return includeSet[table] || includeSet[qualified]
+	return includeSet.Match(table, qualified)
 }
@@ -242,4 +242,94 @@ var _ = Describe("Config", func() {
 			Expect(includeSet["posts"]).To(BeFalse())
 		})
 	})
+	Describe("SQL.GetTableConfig", func() {
+		var sql sqlc.SQL
+
+		BeforeEach(func() {
+			off, removed := "", "removed_at"
+			sql = sqlc.SQL{
+				Codegen: []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Queries:    sqlc.QueryOptions{Exclude: []string{"DeleteUser"}},
+							SoftDelete: sqlc.SoftDeleteOptions{Column: "deleted_at"},
+							Timestamps: sqlc.TimestampOptions{Created: []string{"created_at"}},
+							Columns:    sqlc.ColumnOptions{Readonly: []string{"created_at"}},
+							Overrides: map[string]sqlc.TableOverride{
+								"audit_*": {
+									Queries:    sqlc.QueryOptions{Exclude: []string{"Insert*"}},
+									SoftDelete: &off,
+									Name:       "audit",
+									Plural:     "audit",
+								},
+								"audit_logs": {
+									Timestamps: sqlc.TimestampOptions{Created: []string{"logged_at"}},
+									Name:       "log",
+									Singular:   "log_entry",
+								},
+								"public.users": {
+									Queries:    sqlc.QueryOptions{Include: []string{"CopyUsers"}},
+									SoftDelete: &removed,
+									Columns:    sqlc.ColumnOptions{Exclude: []string{"password"}, Readonly: []string{"email"}},
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("returns the block options when no override matches", func() {
+			config := sql.GetTableConfig("public", "posts")
+			Expect(config.Name).To(BeEmpty())
+			Expect(config.SoftDelete).To(Equal("deleted_at"))
+			Expect(config.QueryExclude).To(Equal(sqlc.Patterns{"DeleteUser": true}))
+			Expect(config.QueryInclude).To(BeEmpty())
+			Expect(config.ExcludeColumns).To(BeEmpty())
+		})
+
+		It("appends the lists of the matching overrides", func() {
+			config := sql.GetTableConfig("public", "users")
+			Expect(config.QueryInclude).To(Equal(sqlc.Patterns{"CopyUsers": true}))
+			Expect(config.QueryExclude).To(Equal(sqlc.Patterns{"DeleteUser": true}))
+			Expect(config.ExcludeColumns).To(HaveKey("password"))
+			Expect(config.ReadonlyColumns).To(Equal(map[string]bool{"created_at": true, "email": true}))
+			Expect(config.SoftDelete).To(Equal("removed_at"))
+
+			config = sql.GetTableConfig("public", "audit_logs")
+			Expect(config.QueryExclude).To(Equal(sqlc.Patterns{"DeleteUser": true, "Insert*": true}))
+			Expect(config.CreatedAt).To(Equal(map[string]bool{"created_at": true, "logged_at": true}))
+		})
+
+		It("lets the most specific override set the other options", func() {
+			config := sql.GetTableConfig("public", "audit_logs")
+			Expect(config.Name).To(Equal("log"))
+			Expect(config.Singular).To(Equal("log_entry"))
+			Expect(config.Plural).To(Equal("audit"))
+			Expect(config.SoftDelete).To(BeEmpty())
+
+			config = sql.GetTableConfig("public", "audit_events")
+			Expect(config.Name).To(Equal("audit"))
+			Expect(config.Singular).To(BeEmpty())
+		})
+	})
+
+	Describe("SQL.GetOut", func() {
+		It("returns the queries path when out is not set", func() {
+			sql := sqlc.SQL{Queries: "queries"}
+			Expect(sql.GetOut()).To(Equal("queries"))
+		})
+
+		It("returns the out directory of the gen-queries plugin", func() {
+			sql := sqlc.SQL{
+				Queries: "queries",
+				Codegen: []sqlc.Codegen{
+					{Plugin: "other", Out: "other"},
+					{Plugin: "gen-queries", Out: "queries/generated"},
+				},
+			}
+			Expect(sql.GetOut()).To(Equal("queries/generated"))
+		})
+	})
 })
@@ -0,0 +1,1169 @@
+package sqlc
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// dollarTag matches the opening tag of a dollar-quoted string.
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// rollbackMarkers start the down section of the migration tools that keep
+// both directions in one file: goose, sql-migrate, tern and dbmate.
+var rollbackMarkers = []string{
+	"-- +goose down",
+	"-- +migrate down",
+	"---- create above / drop below ----",
+	"-- migrate:down",
+}
+
+// LoadCatalogDDL builds a catalog by parsing the DDL statements in the given
+// paths, without connecting to a database. A path may be a single `.sql` file
+// or a directory of migrations, which are applied in file name order; down
+// migrations (`*.down.sql`) and the down sections of goose, sql-migrate, tern
+// and dbmate files are skipped. Unqualified tables are placed in the
+// given default schema, and the statements are read with the syntax of the
+// given engine.
+//
+// CREATE TABLE, CREATE [UNIQUE] INDEX, ALTER TABLE, DROP TABLE, DROP INDEX,
+// CREATE SCHEMA and COMMENT ON statements are applied; any other statement is
+// ignored, as are the indexes of views and other relations it does not know.
+func LoadCatalogDDL(engine, schema string, paths ...string) (*Catalog, error) {
+	builder := &ddlBuilder{
+		catalog: &Catalog{},
+		engine:  engine,
+		schema:  schema,
+	}
+
+	for _, path := range paths {
+		files, err := ddlFiles(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := builder.Apply(forwardDDL(string(data))); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+
+	builder.resolve()
+	return builder.catalog, nil
+}
+
+// ddlFiles returns the migration files for a path in migration order.
+func ddlFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".sql" || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+
+	// ReadDir returns the entries sorted by file name
+	return files, nil
+}
+
+// forwardDDL returns the migration text up to its down section, if any, the
+// way sqlc reads migrations.
+func forwardDDL(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if slices.ContainsFunc(rollbackMarkers, func(marker string) bool { return strings.HasPrefix(line, marker) }) {
+			return strings.Join(lines[:i], "\n")
+		}
+	}
+	return text
+}
+
+// ddlBuilder applies DDL statements to an in-memory catalog.
+type ddlBuilder struct {
+	catalog *Catalog
+	engine  string
+	schema  string
+}
+
+// Apply parses and applies every statement in the given SQL text.
+func (x *ddlBuilder) Apply(text string) error {
+	// Only MySQL starts comments with #, an operator in PostgreSQL
+	tokens, err := tokenize(text, x.engine == "mysql")
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range splitTokens(tokens, ";") {
+		if len(statement) == 0 {
+			continue
+		}
+
+		if err := x.apply(&ddlParser{tokens: statement}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (x *ddlBuilder) apply(p *ddlParser) error {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		switch {
+		case p.accept("SCHEMA"), p.accept("DATABASE"):
+			p.accept("IF", "NOT", "EXISTS")
+			x.getSchema(p.ident(), true)
+		case p.is("UNIQUE"), p.is("INDEX"):
+			return x.createIndex(p)
+		default:
+			// Skip table modifiers such as TEMPORARY or UNLOGGED
+			for p.is("TEMP") || p.is("TEMPORARY") || p.is("UNLOGGED") || p.is("GLOBAL") || p.is("LOCAL") {
+				p.next()
+			}
+			if p.accept("TABLE") {
+				return x.createTable(p)
+			}
+		}
+	case p.accept("ALTER", "TABLE"):
+		return x.alterTable(p)
+	case p.accept("DROP"):
+		switch {
+		case p.accept("TABLE"):
+			p.accept("IF", "EXISTS")
+			for {
+				schema, name := p.name()
+				x.dropTable(schema, name)
+				if !p.acceptSymbol(",") {
+					break
+				}
+			}
+		case p.accept("INDEX"):
+			p.accept("CONCURRENTLY")
+			p.accept("IF", "EXISTS")
+			for {
+				schema, name := p.name()
+				if p.accept("ON") {
+					// MySQL: DROP INDEX name ON table
+					tschema, tname := p.name()
+					if table := x.getTable(tschema, tname); table != nil {
+						table.dropConstraint(name)
+					}
+					break
+				}
+				x.dropIndex(schema, name)
+				if !p.acceptSymbol(",") {
+					break
+				}
+			}
+		case p.accept("SCHEMA"), p.accept("DATABASE"):
+			p.accept("IF", "EXISTS")
+			name := p.ident()
+			x.catalog.Schemas = slices.DeleteFunc(x.catalog.Schemas, func(schema Schema) bool {
+				return schema.Name == name
+			})
+		}
+	case p.accept("COMMENT", "ON"):
+		return x.comment(p)
+	}
+
+	return nil
+}
+
+// createTable applies a CREATE TABLE statement. The CREATE TABLE keywords
+// have already been consumed.
+func (x *ddlBuilder) createTable(p *ddlParser) error {
+	exists := p.accept("IF", "NOT", "EXISTS")
+	schema, name := p.name()
+
+	if x.getTable(schema, name) != nil {
+		if exists {
+			return nil
+		}
+		return fmt.Errorf("table %q already exists", name)
+	}
+
+	table := &Table{Name: name}
+
+	group, ok := p.group()
+	if !ok {
+		// CREATE TABLE ... AS SELECT cannot be described statically
+		return nil
+	}
+
+	for _, element := range splitTokens(group, ",") {
+		table.addElement(&ddlParser{tokens: element})
+	}
+
+	// MySQL table options, e.g. COMMENT = 'text'
+	for !p.done() {
+		if p.accept("COMMENT") {
+			p.acceptSymbol("=")
+			table.Comment = p.next().text
+			continue
+		}
+		p.next()
+	}
+
+	item := x.getSchema(schema, true)
+	item.Tables = append(item.Tables, *table)
+	return nil
+}
+
+// alterTable applies an ALTER TABLE statement. The ALTER TABLE keywords have
+// already been consumed.
+func (x *ddlBuilder) alterTable(p *ddlParser) error {
+	exists := p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	schema, name := p.name()
+
+	table := x.getTable(schema, name)
+	switch {
+	case table == nil && exists:
+		return nil
+	case table == nil:
+		return fmt.Errorf("table %q does not exist", name)
+	}
+
+	for _, action := range splitTokens(p.rest(), ",") {
+		p := &ddlParser{tokens: action}
+
+		switch {
+		case p.accept("ADD"):
+			if p.accept("COLUMN") {
+				p.accept("IF", "NOT", "EXISTS")
+			}
+			table.addElement(p)
+		case p.accept("DROP"):
+			switch {
+			case p.accept("CONSTRAINT"), p.accept("INDEX"), p.accept("KEY"):
+				p.accept("IF", "EXISTS")
+				table.dropConstraint(p.ident())
+			case p.accept("PRIMARY", "KEY"):
+				table.PrimaryKey = nil
+			default:
+				p.accept("COLUMN")
+				p.accept("IF", "EXISTS")
+				table.dropColumn(p.ident())
+			}
+		case p.accept("ALTER"):
+			p.accept("COLUMN")
+			column := table.getColumn(p.ident())
+			if column == nil {
+				continue
+			}
+			switch {
+			case p.accept("SET", "NOT", "NULL"):
+				column.Null = false
+			case p.accept("DROP", "NOT", "NULL"):
+				column.Null = true
+			case p.accept("SET", "DATA", "TYPE"), p.accept("TYPE"):
+				column.Type = p.columnType()
+			case p.accept("SET", "DEFAULT"):
+				column.Default = p.expr()
+			case p.accept("DROP", "DEFAULT"):
+				column.Default = ""
+			case p.accept("ADD", "GENERATED"):
+				column.Identity = true
+			case p.accept("DROP", "IDENTITY"):
+				column.Identity = false
+			case p.accept("DROP", "EXPRESSION"):
+				column.Generated = ""
+			}
+		case p.accept("RENAME"):
+			switch {
+			case p.accept("TO"), p.accept("AS"):
+				_, table.Name = p.name()
+			default:
+				p.accept("COLUMN")
+				from := p.ident()
+				p.accept("TO")
+				table.renameColumn(from, p.ident())
+			}
+		}
+	}
+
+	return nil
+}
+
+// createIndex applies a CREATE [UNIQUE] INDEX statement. The CREATE keyword
+// has already been consumed.
+func (x *ddlBuilder) createIndex(p *ddlParser) error {
+	index := Index{Unique: p.accept("UNIQUE")}
+	p.accept("INDEX")
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+
+	if !p.is("ON") {
+		_, index.Name = p.name()
+	}
+
+	p.accept("ON")
+	p.accept("ONLY")
+	schema, name := p.name()
+
+	table := x.getTable(schema, name)
+	if table == nil {
+		// Views, materialized views and CREATE TABLE ... AS tables are not
+		// described, and neither are their indexes
+		return nil
+	}
+
+	if p.accept("USING") {
+		p.next()
+	}
+
+	group, _ := p.group()
+	index.Parts = indexParts(group)
+	index.Name = cmp.Or(index.Name, table.Name+"_"+strings.Join(index.columns(), "_")+"_idx")
+
+	// Skip INCLUDE, WITH and TABLESPACE up to the predicate of a partial index
+	for !p.done() {
+		if p.accept("WHERE") {
+			index.Where = joinTokens(p.rest())
+			break
+		}
+		if _, ok := p.group(); !ok {
+			p.next()
+		}
+	}
+
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+// comment applies a COMMENT ON statement. The COMMENT ON keywords have
+// already been consumed.
+func (x *ddlBuilder) comment(p *ddlParser) error {
+	switch {
+	case p.accept("TABLE"):
+		schema, name := p.name()
+		if p.accept("IS") {
+			if table := x.getTable(schema, name); table != nil {
+				table.Comment = p.next().text
+			}
+		}
+	case p.accept("COLUMN"):
+		parts := p.path()
+		if len(parts) < 2 || !p.accept("IS") {
+			return nil
+		}
+
+		var schema string
+		if len(parts) > 2 {
+			schema = parts[len(parts)-3]
+		}
+
+		if table := x.getTable(schema, parts[len(parts)-2]); table != nil {
+			if column := table.getColumn(parts[len(parts)-1]); column != nil {
+				column.Comment = p.next().text
+			}
+		}
+	case p.accept("SCHEMA"):
+		name := p.ident()
+		if p.accept("IS") {
+			if schema := x.getSchema(name, false); schema != nil {
+				schema.Comment = p.next().text
+			}
+		}
+	}
+
+	return nil
+}
+
+// getSchema returns the named schema, creating it when create is true.
+func (x *ddlBuilder) getSchema(name string, create bool) *Schema {
+	name = cmp.Or(name, x.schema)
+
+	for i := range x.catalog.Schemas {
+		if x.catalog.Schemas[i].Name == name {
+			return &x.catalog.Schemas[i]
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	x.catalog.Schemas = append(x.catalog.Schemas, Schema{Name: name})
+	return &x.catalog.Schemas[len(x.catalog.Schemas)-1]
+}
+
+// getTable returns the named table in the given schema.
+func (x *ddlBuilder) getTable(schema, name string) *Table {
+	item := x.getSchema(schema, false)
+	if item == nil {
+		return nil
+	}
+
+	for i := range item.Tables {
+		if item.Tables[i].Name == name {
+			return &item.Tables[i]
+		}
+	}
+
+	return nil
+}
+
+func (x *ddlBuilder) dropTable(schema, name string) {
+	if item := x.getSchema(schema, false); item != nil {
+		item.Tables = slices.DeleteFunc(item.Tables, func(table Table) bool {
+			return table.Name == name
+		})
+	}
+}
+
+func (x *ddlBuilder) dropIndex(schema, name string) {
+	if item := x.getSchema(schema, false); item != nil {
+		for i := range item.Tables {
+			item.Tables[i].dropConstraint(name)
+		}
+	}
+}
+
+// resolve fills in foreign key columns that reference the primary key of
+// the referenced table implicitly.
+func (x *ddlBuilder) resolve() {
+	for i := range x.catalog.Schemas {
+		schema := &x.catalog.Schemas[i]
+
+		for j := range schema.Tables {
+			for k := range schema.Tables[j].ForeignKeys {
+				fk := &schema.Tables[j].ForeignKeys[k]
+				if len(fk.References.Columns) > 0 {
+					continue
+				}
+
+				if table := x.getTable(cmp.Or(fk.References.Schema, schema.Name), fk.References.Table); table != nil && table.PrimaryKey != nil {
+					fk.References.Columns = table.PrimaryKey.columns()
+				}
+			}
+		}
+	}
+}
+
+// addElement adds a column definition or a table constraint to the table.
+func (x *Table) addElement(p *ddlParser) {
+	var name string
+	if p.accept("CONSTRAINT") {
+		name = p.ident()
+	}
+
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		group, _ := p.group()
+		x.setPrimaryKey(name, indexParts(group))
+	case p.accept("UNIQUE"):
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		if !p.isSymbol("(") {
+			name = cmp.Or(name, p.ident())
+		}
+		group, _ := p.group()
+		x.addUnique(name, indexParts(group))
+	case p.accept("FOREIGN", "KEY"):
+		if !p.isSymbol("(") {
+			name = cmp.Or(name, p.ident())
+		}
+		group, _ := p.group()
+		x.addForeignKey(name, identifiers(group), p)
+	case p.isIndex():
+		// MySQL inline index
+		p.next()
+		if !p.isSymbol("(") {
+			name = p.ident()
+		}
+		group, _ := p.group()
+		index := Index{Name: name, Parts: indexParts(group)}
+		index.Name = cmp.Or(index.Name, x.Name+"_"+strings.Join(index.columns(), "_")+"_idx")
+		x.Indexes = append(x.Indexes, index)
+	case p.is("CHECK"), p.is("EXCLUDE"), p.is("LIKE"), p.is("FULLTEXT"), p.is("SPATIAL"):
+		// Not relevant for query generation
+	case name == "":
+		x.addColumn(p)
+	}
+}
+
+// addColumn adds a column definition, including its inline constraints.
+func (x *Table) addColumn(p *ddlParser) {
+	column := Column{
+		Name: p.ident(),
+		Type: p.columnType(),
+		Null: true,
+	}
+	// PostgreSQL serial types are integers backed by a sequence
+	column.Identity = serialTypes[strings.ToLower(column.Type)]
+
+	// Constraints are applied after the column has been added
+	var constraints []func()
+
+	for !p.done() {
+		var name string
+		if p.accept("CONSTRAINT") {
+			name = p.ident()
+		}
+
+		switch {
+		case p.accept("NOT", "NULL"):
+			column.Null = false
+		case p.accept("NULL"):
+			column.Null = true
+		case p.accept("PRIMARY", "KEY"):
+			column.Null = false
+			constraints = append(constraints, func() {
+				x.setPrimaryKey(name, []IndexPart{{Column: column.Name}})
+			})
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			constraints = append(constraints, func() {
+				x.addUnique(name, []IndexPart{{Column: column.Name}})
+			})
+		case p.accept("REFERENCES"):
+			// Rewind so that addForeignKey parses the REFERENCES clause
+			p.pos--
+			columns := []string{column.Name}
+			fk := &ddlParser{tokens: p.clause()}
+			constraints = append(constraints, func() {
+				x.addForeignKey(name, columns, fk)
+			})
+		case p.accept("DEFAULT"):
+			column.Default = p.expr()
+		case p.accept("GENERATED"):
+			if !p.accept("ALWAYS") {
+				p.accept("BY", "DEFAULT")
+			}
+			p.accept("AS")
+			column.Identity, column.Generated = p.generated()
+		case p.accept("AS"):
+			// MySQL and SQLite allow generated columns without GENERATED ALWAYS
+			column.Identity, column.Generated = p.generated()
+		case p.accept("AUTO_INCREMENT"), p.accept("AUTOINCREMENT"), p.accept("IDENTITY"):
+			column.Identity = true
+		case p.accept("COMMENT"):
+			column.Comment = p.next().text
+		case p.isSymbol("("):
+			p.group()
+		default:
+			p.next()
+		}
+	}
+
+	x.Columns = append(x.Columns, column)
+
+	for _, constraint := range constraints {
+		constraint()
+	}
+}
+
+func (x *Table) setPrimaryKey(name string, parts []IndexPart) {
+	x.PrimaryKey = &Index{
+		Name:   cmp.Or(name, x.Name+"_pkey"),
+		Unique: true,
+		Parts:  parts,
+	}
+
+	// Primary key columns are implicitly NOT NULL
+	for _, part := range parts {
+		if column := x.getColumn(part.Column); column != nil {
+			column.Null = false
+		}
+	}
+}
+
+func (x *Table) addUnique(name string, parts []IndexPart) {
+	index := Index{Name: name, Unique: true, Parts: parts}
+	index.Name = cmp.Or(index.Name, x.Name+"_"+strings.Join(index.columns(), "_")+"_key")
+	x.Indexes = append(x.Indexes, index)
+}
+
+// addForeignKey adds a foreign key; p is positioned at the REFERENCES clause.
+func (x *Table) addForeignKey(name string, columns []string, p *ddlParser) {
+	if !p.accept("REFERENCES") {
+		return
+	}
+
+	fk := ForeignKey{
+		Name:    cmp.Or(name, x.Name+"_"+strings.Join(columns, "_")+"_fkey"),
+		Columns: columns,
+	}
+
+	fk.References.Schema, fk.References.Table = p.name()
+	if group, ok := p.group(); ok {
+		fk.References.Columns = identifiers(group)
+	}
+
+	x.ForeignKeys = append(x.ForeignKeys, fk)
+}
+
+// getColumn returns a pointer to the named column so it can be modified in
+// place, unlike GetColumn which returns a copy.
+func (x *Table) getColumn(name string) *Column {
+	for i := range x.Columns {
+		if x.Columns[i].Name == name {
+			return &x.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (x *Table) dropColumn(name string) {
+	x.Columns = slices.DeleteFunc(x.Columns, func(column Column) bool {
+		return column.Name == name
+	})
+
+	// Drop the indexes and foreign keys that depend on the column
+	x.Indexes = slices.DeleteFunc(x.Indexes, func(index Index) bool {
+		return slices.Contains(index.columns(), name)
+	})
+	x.ForeignKeys = slices.DeleteFunc(x.ForeignKeys, func(fk ForeignKey) bool {
+		return slices.Contains(fk.Columns, name)
+	})
+}
+
+func (x *Table) renameColumn(from, to string) {
+	if column := x.getColumn(from); column != nil {
+		column.Name = to
+	}
+
+	rename := func(parts []IndexPart) {
+		for i := range parts {
+			if parts[i].Column == from {
+				parts[i].Column = to
+			}
+		}
+	}
+
+	if x.PrimaryKey != nil {
+		rename(x.PrimaryKey.Parts)
+	}
+
+	for i := range x.Indexes {
+		rename(x.Indexes[i].Parts)
+	}
+
+	for i := range x.ForeignKeys {
+		for j, column := range x.ForeignKeys[i].Columns {
+			if column == from {
+				x.ForeignKeys[i].Columns[j] = to
+			}
+		}
+	}
+}
+
+// dropConstraint removes the primary key, index or foreign key with the
+// given name.
+func (x *Table) dropConstraint(name string) {
+	if x.PrimaryKey != nil && x.PrimaryKey.Name == name {
+		x.PrimaryKey = nil
+	}
+
+	x.Indexes = slices.DeleteFunc(x.Indexes, func(index Index) bool {
+		return index.Name == name
+	})
+	x.ForeignKeys = slices.DeleteFunc(x.ForeignKeys, func(fk ForeignKey) bool {
+		return fk.Name == name
+	})
+}
+
+// columns returns the column names of the index parts.
+func (x *Index) columns() []string {
+	columns := make([]string, 0, len(x.Parts))
+	for _, part := range x.Parts {
+		columns = append(columns, cmp.Or(part.Column, "expr"))
+	}
+	return columns
+}
+
+// indexParts parses a parenthesized index part list.
+func indexParts(tokens []ddlToken) []IndexPart {
+	var parts []IndexPart
+
+	for _, element := range splitTokens(tokens, ",") {
+		p := &ddlParser{tokens: element}
+
+		var part IndexPart
+		if len(element) > 0 && (element[0].kind == tokenWord || element[0].kind == tokenQuoted) &&
+			(len(element) == 1 || element[1].kind != tokenSymbol || element[1].text != "(") {
+			part.Column = p.ident()
+		} else {
+			// Expression part: consume up to the ordering keywords
+			var expr []ddlToken
+			for !p.done() && !p.is("ASC") && !p.is("DESC") && !p.is("NULLS") && !p.is("COLLATE") {
+				expr = append(expr, p.next())
+			}
+			part.Expr = joinTokens(expr)
+			if len(expr) > 0 && expr[0].text == "(" {
+				// Unwrap a parenthesized expression
+				part.Expr = joinTokens(expr[1 : len(expr)-1])
+			}
+		}
+
+		for !p.done() {
+			if p.accept("DESC") {
+				part.Desc = true
+				continue
+			}
+			p.next()
+		}
+
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// identifiers parses a parenthesized identifier list.
+func identifiers(tokens []ddlToken) []string {
+	var names []string
+	for _, element := range splitTokens(tokens, ",") {
+		p := &ddlParser{tokens: element}
+		names = append(names, p.ident())
+	}
+	return names
+}
+
+// serialTypes are the PostgreSQL pseudo-types that create an identity column.
+var serialTypes = map[string]bool{
+	"serial": true, "serial2": true, "serial4": true, "serial8": true,
+	"smallserial": true, "bigserial": true,
+}
+
+// columnKeywords are the keywords that end the data type of a column
+// definition.
+var columnKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "PRIMARY": true, "UNIQUE": true, "REFERENCES": true,
+	"DEFAULT": true, "CONSTRAINT": true, "CHECK": true, "GENERATED": true, "COLLATE": true,
+	"AUTO_INCREMENT": true, "AUTOINCREMENT": true, "COMMENT": true, "IDENTITY": true,
+	"ON": true, "AS": true, "KEY": true, "USING": true,
+}
+
+// tokenKind classifies a DDL token.
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+// ddlToken is a lexical token of a DDL statement.
+type ddlToken struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits SQL text into tokens, dropping whitespace and comments,
+// including those starting with # when hashComments is set.
+func tokenize(text string, hashComments bool) ([]ddlToken, error) {
+	var tokens []ddlToken
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(text[i:], "--"), r == '#' && hashComments:
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			i += end
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2 + end + 2
+		case r == '\'':
+			var value strings.Builder
+			for i++; ; i++ {
+				if i >= len(text) {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				if text[i] == '\'' {
+					// A doubled quote is an escaped quote
+					if i+1 < len(text) && text[i+1] == '\'' {
+						value.WriteByte('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				value.WriteByte(text[i])
+			}
+			tokens = append(tokens, ddlToken{kind: tokenString, text: value.String()})
+		case r == '"', r == '`':
+			end := strings.IndexRune(text[i+1:], r)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			tokens = append(tokens, ddlToken{kind: tokenQuoted, text: text[i+1 : i+1+end]})
+			i += 1 + end + 1
+		case r == '$' && dollarTag.MatchString(text[i:]):
+			// Dollar-quoted string, e.g. a function body
+			tag := dollarTag.FindString(text[i:])
+			end := strings.Index(text[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated dollar-quoted string")
+			}
+			tokens = append(tokens, ddlToken{kind: tokenString, text: text[i+len(tag) : i+len(tag)+end]})
+			i += len(tag) + end + len(tag)
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ddlToken{kind: tokenNumber, text: text[i:end]})
+			i = end
+		case unicode.IsLetter(r), r == '_':
+			end := i
+			for end < len(text) {
+				r, size := utf8.DecodeRuneInString(text[end:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, ddlToken{kind: tokenWord, text: text[i:end]})
+			i = end
+		case strings.HasPrefix(text[i:], "::"):
+			tokens = append(tokens, ddlToken{kind: tokenSymbol, text: "::"})
+			i += 2
+		default:
+			tokens = append(tokens, ddlToken{kind: tokenSymbol, text: string(r)})
+			i += size
+		}
+	}
+
+	return tokens, nil
+}
+
+// splitTokens splits tokens on a symbol that is not nested in parentheses.
+func splitTokens(tokens []ddlToken, symbol string) [][]ddlToken {
+	var (
+		items [][]ddlToken
+		depth int
+		start int
+	)
+
+	for i, token := range tokens {
+		if token.kind != tokenSymbol {
+			continue
+		}
+
+		switch token.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case symbol:
+			if depth == 0 {
+				items = append(items, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if start < len(tokens) {
+		items = append(items, tokens[start:])
+	}
+
+	return items
+}
+
+// joinTokens renders tokens back into SQL text.
+func joinTokens(tokens []ddlToken) string {
+	var builder strings.Builder
+
+	for i, token := range tokens {
+		text := token.text
+		switch token.kind {
+		case tokenWord:
+			text = strings.ToLower(text)
+		case tokenString:
+			text = "'" + strings.ReplaceAll(text, "'", "''") + "'"
+		case tokenQuoted:
+			text = `"` + text + `"`
+		}
+
+		if i > 0 {
+			previous := tokens[i-1]
+			joined := token.kind == tokenSymbol && slices.Contains([]string{"(", ")", ",", "[", "]", "::", "."}, text) ||
+				previous.kind == tokenSymbol && slices.Contains([]string{"(", "[", "::", "."}, previous.text)
+			if !joined {
+				builder.WriteByte(' ')
+			}
+		}
+
+		builder.WriteString(text)
+	}
+
+	return builder.String()
+}
+
+// ddlParser is a cursor over the tokens of a single statement.
+type ddlParser struct {
+	tokens []ddlToken
+	pos    int
+}
+
+func (x *ddlParser) done() bool {
+	return x.pos >= len(x.tokens)
+}
+
+func (x *ddlParser) next() ddlToken {
+	if x.done() {
+		return ddlToken{}
+	}
+	token := x.tokens[x.pos]
+	x.pos++
+	return token
+}
+
+// is reports whether the next tokens are the given keywords.
+func (x *ddlParser) is(words ...string) bool {
+	for i, word := range words {
+		if x.pos+i >= len(x.tokens) {
+			return false
+		}
+		token := x.tokens[x.pos+i]
+		if token.kind != tokenWord || !strings.EqualFold(token.text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the given keywords when they are next.
+func (x *ddlParser) accept(words ...string) bool {
+	if !x.is(words...) {
+		return false
+	}
+	x.pos += len(words)
+	return true
+}
+
+// isIndex reports whether the next tokens start a MySQL inline index: KEY or
+// INDEX followed by the columns in parentheses, optionally after the index
+// name. Columns named key or index are followed by their type instead, whose
+// parameters are numbers or strings rather than columns.
+func (x *ddlParser) isIndex() bool {
+	if !x.is("KEY") && !x.is("INDEX") {
+		return false
+	}
+
+	// at returns the token at the given offset from the cursor
+	at := func(offset int) ddlToken {
+		if x.pos+offset >= len(x.tokens) {
+			return ddlToken{}
+		}
+		return x.tokens[x.pos+offset]
+	}
+
+	open := func(token ddlToken) bool {
+		return token.kind == tokenSymbol && token.text == "("
+	}
+	column := func(token ddlToken) bool {
+		return token.text != "" && (token.kind == tokenWord || token.kind == tokenQuoted)
+	}
+
+	switch {
+	case open(at(1)):
+		return true
+	case column(at(1)) && open(at(2)):
+		return column(at(3))
+	}
+	return false
+}
+
+func (x *ddlParser) isSymbol(symbol string) bool {
+	return !x.done() && x.tokens[x.pos].kind == tokenSymbol && x.tokens[x.pos].text == symbol
+}
+
+func (x *ddlParser) acceptSymbol(symbol string) bool {
+	if !x.isSymbol(symbol) {
+		return false
+	}
+	x.pos++
+	return true
+}
+
+// ident consumes an identifier. Unquoted identifiers are folded to lower case.
+func (x *ddlParser) ident() string {
+	token := x.next()
+	if token.kind == tokenWord {
+		return strings.ToLower(token.text)
+	}
+	return token.text
+}
+
+// path consumes a dot-separated identifier path.
+func (x *ddlParser) path() []string {
+	parts := []string{x.ident()}
+	for x.acceptSymbol(".") {
+		parts = append(parts, x.ident())
+	}
+	return parts
+}
+
+// name consumes an optionally schema-qualified object name.
+func (x *ddlParser) name() (string, string) {
+	parts := x.path()
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+// group consumes a parenthesized group and returns the tokens inside it.
+func (x *ddlParser) group() ([]ddlToken, bool) {
+	if !x.isSymbol("(") {
+		return nil, false
+	}
+
+	depth := 0
+	for i := x.pos; i < len(x.tokens); i++ {
+		if x.tokens[i].kind != tokenSymbol {
+			continue
+		}
+		switch x.tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				group := x.tokens[x.pos+1 : i]
+				x.pos = i + 1
+				return group, true
+			}
+		}
+	}
+
+	group := x.tokens[x.pos+1:]
+	x.pos = len(x.tokens)
+	return group, true
+}
+
+// rest consumes and returns the remaining tokens.
+func (x *ddlParser) rest() []ddlToken {
+	tokens := x.tokens[x.pos:]
+	x.pos = len(x.tokens)
+	return tokens
+}
+
+// clause consumes a keyword followed by everything up to the next column
+// constraint keyword.
+func (x *ddlParser) clause() []ddlToken {
+	start := x.pos
+	x.pos++
+
+	for !x.done() {
+		token := x.tokens[x.pos]
+		// ON belongs to the referential actions of the clause
+		if token.kind == tokenWord && columnKeywords[strings.ToUpper(token.text)] && !strings.EqualFold(token.text, "ON") {
+			break
+		}
+		if token.kind == tokenSymbol && token.text == "(" {
+			x.group()
+			continue
+		}
+		x.pos++
+	}
+
+	return x.tokens[start:x.pos]
+}
+
+// expr consumes an expression up to the next column constraint keyword.
+func (x *ddlParser) expr() string {
+	start := x.pos
+
+	for !x.done() {
+		token := x.tokens[x.pos]
+		if x.pos > start && token.kind == tokenWord && columnKeywords[strings.ToUpper(token.text)] {
+			break
+		}
+
+		if token.kind == tokenSymbol && token.text == "(" {
+			x.group()
+			continue
+		}
+
+		x.pos++
+	}
+
+	return joinTokens(x.tokens[start:x.pos])
+}
+
+// generated consumes the rest of a GENERATED ... AS clause. It reports
+// whether the column is an identity column, or else returns the generation
+// expression.
+func (x *ddlParser) generated() (bool, string) {
+	if x.accept("IDENTITY") {
+		// Sequence options
+		x.group()
+		return true, ""
+	}
+
+	group, _ := x.group()
+	// STORED or VIRTUAL
+	x.accept("STORED")
+	x.accept("VIRTUAL")
+	return false, joinTokens(group)
+}
+
+// columnType consumes the data type of a column definition.
+func (x *ddlParser) columnType() string {
+	var tokens []ddlToken
+
+	for !x.done() {
+		token := x.tokens[x.pos]
+		if token.kind == tokenWord && columnKeywords[strings.ToUpper(token.text)] {
+			break
+		}
+
+		if token.kind == tokenSymbol && token.text == "(" {
+			start := x.pos
+			x.group()
+			tokens = append(tokens, x.tokens[start:x.pos]...)
+			continue
+		}
+
+		tokens = append(tokens, token)
+		x.pos++
+	}
+
+	return joinTokens(tokens)
+}
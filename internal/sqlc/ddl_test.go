@@ -0,0 +1,334 @@
+package sqlc_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadCatalogDDL", func() {
+	// load parses the given DDL from a temporary file
+	load := func(ddl string) (*sqlc.Catalog, error) {
+		path := filepath.Join(GinkgoT().TempDir(), "schema.sql")
+		Expect(os.WriteFile(path, []byte(ddl), 0o600)).To(Succeed())
+		return sqlc.LoadCatalogDDL("postgresql", "public", path)
+	}
+
+	It("applies the migrations in order", func() {
+		catalog, err := sqlc.LoadCatalogDDL("postgresql", "public", "./ddl_test")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.Schemas).To(HaveLen(1))
+		Expect(catalog.Schemas[0].Name).To(Equal("public"))
+
+		// The down migration and the dropped table are not applied
+		Expect(catalog.Schemas[0].Tables).To(HaveLen(2))
+
+		users := catalog.GetTable("users")
+		Expect(users).NotTo(BeNil())
+		Expect(users.Comment).To(Equal("User accounts"))
+		Expect(users.Columns).To(HaveLen(3))
+		Expect(users.Columns[0]).To(Equal(sqlc.Column{
+			Name:       "id",
+			Type:       "integer",
+			Attributes: sqlc.Attributes{Comment: "Primary key"},
+		}))
+		Expect(users.Columns[1].Type).To(Equal("varchar(255)"))
+		Expect(users.Columns[2].Null).To(BeTrue())
+		Expect(users.PrimaryKey.Name).To(Equal("users_pkey"))
+		Expect(users.PrimaryKey.Parts).To(Equal([]sqlc.IndexPart{{Column: "id"}}))
+		Expect(users.Indexes).To(Equal([]sqlc.Index{
+			{Name: "idx_users_email", Unique: true, Parts: []sqlc.IndexPart{{Column: "email"}}},
+		}))
+
+		posts := catalog.GetTable("posts")
+		Expect(posts).NotTo(BeNil())
+		Expect(posts.GetColumn("legacy_id")).To(BeNil())
+		Expect(posts.PrimaryKey.Name).To(Equal("posts_pkey"))
+		Expect(posts.ForeignKeys).To(HaveLen(2))
+		Expect(posts.ForeignKeys[0].Name).To(Equal("fk_posts_user_id"))
+		Expect(posts.ForeignKeys[0].Columns).To(Equal([]string{"user_id"}))
+		Expect(posts.ForeignKeys[0].References.Table).To(Equal("users"))
+		Expect(posts.ForeignKeys[0].References.Columns).To(Equal([]string{"id"}))
+		// The referenced primary key is resolved when columns are omitted
+		Expect(posts.ForeignKeys[1].References.Columns).To(Equal([]string{"id"}))
+
+		Expect(posts.Indexes).To(HaveLen(3))
+		Expect(posts.Indexes[1].Name).To(Equal("idx_posts_title_expr"))
+		Expect(posts.Indexes[1].Parts).To(Equal([]sqlc.IndexPart{{Expr: "lower(title)"}}))
+	})
+
+	It("produces the same queries as the equivalent catalog file", func() {
+		// render returns the content of the rendered files by path
+		render := func(catalog *sqlc.Catalog) map[string]string {
+			generator := &sqlc.Generator{
+				Catalog: catalog,
+				Config: &sqlc.Config{
+					SQL: []sqlc.SQL{{Engine: "postgresql", Queries: "query"}},
+				},
+			}
+			files, err := generator.Render()
+			Expect(err).NotTo(HaveOccurred())
+
+			contents := make(map[string]string, len(files))
+			for _, file := range files {
+				contents[file.Path] = string(file.Content)
+			}
+			return contents
+		}
+
+		expected, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		actual, err := sqlc.LoadCatalogDDL("postgresql", "public", "./ddl_test")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(render(actual)).To(Equal(render(expected)))
+	})
+
+	It("parses inline constraints, schemas and MySQL syntax", func() {
+		catalog, err := load(`
+			CREATE SCHEMA IF NOT EXISTS auth;
+
+			CREATE TABLE auth.accounts (
+				` + "`id`" + ` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+				"Email" VARCHAR(255) NOT NULL UNIQUE,
+				balance NUMERIC(10, 2) DEFAULT 0 NOT NULL,
+				status VARCHAR(16) DEFAULT 'active' COMMENT 'Account status',
+				created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+				tags TEXT[],
+				PRIMARY KEY (id),
+				KEY idx_accounts_status (status DESC)
+			) ENGINE=InnoDB COMMENT='Accounts';
+
+			CREATE TABLE sessions (
+				id integer CONSTRAINT sessions_pk PRIMARY KEY,
+				account_id bigint REFERENCES auth.accounts (id) ON DELETE SET NULL
+			);
+
+			CREATE FUNCTION touch() RETURNS trigger AS $$
+			BEGIN
+				NEW.updated_at = now();
+				RETURN NEW;
+			END;
+			$$ LANGUAGE plpgsql;
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.Schemas).To(HaveLen(2))
+		Expect(catalog.Schemas[0].Name).To(Equal("auth"))
+		Expect(catalog.Schemas[1].Name).To(Equal("public"))
+
+		accounts := catalog.GetTable("accounts")
+		Expect(accounts.Comment).To(Equal("Accounts"))
+		Expect(accounts.Columns).To(HaveLen(6))
+		Expect(accounts.Columns[0].Type).To(Equal("bigint unsigned"))
+		Expect(accounts.Columns[0].Null).To(BeFalse())
+		Expect(accounts.Columns[1].Name).To(Equal("Email"))
+		Expect(accounts.Columns[2].Type).To(Equal("numeric(10, 2)"))
+		Expect(accounts.Columns[2].Null).To(BeFalse())
+		Expect(accounts.Columns[3].Comment).To(Equal("Account status"))
+		Expect(accounts.Columns[3].Null).To(BeTrue())
+		Expect(accounts.Columns[4].Type).To(Equal("timestamp with time zone"))
+		Expect(accounts.Columns[5].Type).To(Equal("text[]"))
+		Expect(accounts.PrimaryKey.Parts).To(Equal([]sqlc.IndexPart{{Column: "id"}}))
+		Expect(accounts.Indexes).To(Equal([]sqlc.Index{
+			{Name: "accounts_Email_key", Unique: true, Parts: []sqlc.IndexPart{{Column: "Email"}}},
+			{Name: "idx_accounts_status", Parts: []sqlc.IndexPart{{Column: "status", Desc: true}}},
+		}))
+
+		sessions := catalog.GetTable("sessions")
+		Expect(sessions.PrimaryKey.Name).To(Equal("sessions_pk"))
+		Expect(sessions.ForeignKeys).To(HaveLen(1))
+		Expect(sessions.ForeignKeys[0].Name).To(Equal("sessions_account_id_fkey"))
+		Expect(sessions.ForeignKeys[0].References.Schema).To(Equal("auth"))
+		Expect(sessions.ForeignKeys[0].References.Table).To(Equal("accounts"))
+		Expect(sessions.ForeignKeys[0].References.Columns).To(Equal([]string{"id"}))
+	})
+
+	It("resolves implicit foreign key columns in the referenced schema", func() {
+		catalog, err := load(`
+			CREATE TABLE auth.users (uid integer PRIMARY KEY);
+			CREATE TABLE users (id integer PRIMARY KEY);
+			CREATE TABLE tokens (user_id integer REFERENCES auth.users);
+		`)
+		Expect(err).NotTo(HaveOccurred())
+
+		tokens := catalog.GetSchemaTable("public", "tokens")
+		Expect(tokens.ForeignKeys[0].References.Columns).To(Equal([]string{"uid"}))
+	})
+
+	It("tells columns named key or index apart from MySQL inline indexes", func() {
+		catalog, err := load(`
+			CREATE TABLE settings (key text PRIMARY KEY, value text, index integer);
+			CREATE TABLE options (
+				key varchar(64) NOT NULL,
+				index varchar(32),
+				KEY options_index_idx (index),
+				INDEX (key)
+			);
+		`)
+		Expect(err).NotTo(HaveOccurred())
+
+		settings := catalog.GetTable("settings")
+		Expect(settings.Columns).To(HaveLen(3))
+		Expect(settings.GetColumn("index").Type).To(Equal("integer"))
+		Expect(settings.PrimaryKey.Parts).To(Equal([]sqlc.IndexPart{{Column: "key"}}))
+		Expect(settings.Indexes).To(BeEmpty())
+
+		options := catalog.GetTable("options")
+		Expect(options.Columns).To(HaveLen(2))
+		Expect(options.GetColumn("key").Type).To(Equal("varchar(64)"))
+		Expect(options.Indexes).To(Equal([]sqlc.Index{
+			{Name: "options_index_idx", Parts: []sqlc.IndexPart{{Column: "index"}}},
+			{Name: "options_key_idx", Parts: []sqlc.IndexPart{{Column: "key"}}},
+		}))
+	})
+
+	It("reads # as a comment on MySQL only", func() {
+		catalog, err := load(`
+			CREATE TABLE flags (
+				a integer,
+				b integer DEFAULT (1 # 2),
+				c text,
+				CHECK (a # b > 0)
+			);
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.GetTable("flags").Columns).To(HaveLen(3))
+		Expect(catalog.GetTable("flags").GetColumn("b").Default).To(Equal("(1 # 2)"))
+
+		path := filepath.Join(GinkgoT().TempDir(), "schema.sql")
+		Expect(os.WriteFile(path, []byte("CREATE TABLE flags (\n  a integer, # the flag\n  b integer\n);\n"), 0o600)).To(Succeed())
+
+		catalog, err = sqlc.LoadCatalogDDL("mysql", "", path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.GetTable("flags").Columns).To(HaveLen(2))
+	})
+
+	It("applies ALTER TABLE actions", func() {
+		catalog, err := load(`
+			CREATE TABLE items (id integer, code text, label text);
+			ALTER TABLE ONLY items ADD CONSTRAINT items_pkey PRIMARY KEY (id);
+			ALTER TABLE items ADD COLUMN IF NOT EXISTS price integer NOT NULL;
+			ALTER TABLE items ALTER COLUMN label SET NOT NULL, ADD CONSTRAINT items_code_key UNIQUE (code);
+			ALTER TABLE items RENAME COLUMN code TO sku;
+			ALTER TABLE items DROP CONSTRAINT items_code_key;
+			ALTER TABLE items ADD CONSTRAINT items_sku_key UNIQUE (sku);
+			ALTER TABLE items RENAME TO products;
+		`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(catalog.GetTable("items")).To(BeNil())
+		products := catalog.GetTable("products")
+		Expect(products).NotTo(BeNil())
+		Expect(products.PrimaryKey.Parts).To(Equal([]sqlc.IndexPart{{Column: "id"}}))
+		Expect(products.GetColumn("id").Null).To(BeFalse())
+		Expect(products.GetColumn("sku")).NotTo(BeNil())
+		Expect(products.GetColumn("label").Null).To(BeFalse())
+		Expect(products.GetColumn("price").Null).To(BeFalse())
+		Expect(products.Indexes).To(Equal([]sqlc.Index{
+			{Name: "items_sku_key", Unique: true, Parts: []sqlc.IndexPart{{Column: "sku"}}},
+		}))
+	})
+
+	It("parses defaults, identity and generated columns", func() {
+		catalog, err := load(`
+			CREATE TABLE events (
+				id bigint GENERATED ALWAYS AS IDENTITY (START WITH 100),
+				seq bigserial,
+				kind text NOT NULL DEFAULT 'info',
+				payload text,
+				size integer GENERATED ALWAYS AS (length(payload)) STORED,
+				created_at timestamptz DEFAULT now() NOT NULL
+			);
+			CREATE TABLE logs (id int AUTO_INCREMENT PRIMARY KEY, line text AS (upper(id)) VIRTUAL);
+			ALTER TABLE events ALTER COLUMN payload SET DEFAULT '{}';
+			ALTER TABLE events ALTER COLUMN kind DROP DEFAULT;
+		`)
+		Expect(err).NotTo(HaveOccurred())
+
+		events := catalog.GetTable("events")
+		Expect(events.GetColumn("id").Identity).To(BeTrue())
+		Expect(events.GetColumn("seq").Identity).To(BeTrue())
+		Expect(events.GetColumn("kind").Default).To(BeEmpty())
+		Expect(events.GetColumn("payload").Default).To(Equal("'{}'"))
+		Expect(events.GetColumn("size").Generated).To(Equal("length(payload)"))
+		Expect(events.GetColumn("created_at").Default).To(Equal("now()"))
+		Expect(events.GetColumn("created_at").Null).To(BeFalse())
+
+		logs := catalog.GetTable("logs")
+		Expect(logs.GetColumn("id").Identity).To(BeTrue())
+		Expect(logs.GetColumn("line").Generated).To(Equal("upper(id)"))
+	})
+
+	It("reads the up section of goose and dbmate migrations only", func() {
+		for _, ddl := range []string{
+			"-- +goose Up\nCREATE TABLE users (id integer PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE users;\n",
+			"-- migrate:up\nCREATE TABLE users (id integer PRIMARY KEY);\n\n-- migrate:down\nDROP TABLE users;\n",
+		} {
+			catalog, err := load(ddl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.GetTable("users")).NotTo(BeNil(), ddl)
+		}
+	})
+
+	It("keeps the predicate of partial indexes", func() {
+		catalog, err := load(`
+			CREATE TABLE users (id integer PRIMARY KEY, email text, deleted_at timestamptz);
+			CREATE UNIQUE INDEX users_email_key ON users (email) INCLUDE (id) WHERE deleted_at IS NULL;
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.GetTable("users").Indexes).To(Equal([]sqlc.Index{
+			{Name: "users_email_key", Unique: true, Parts: []sqlc.IndexPart{{Column: "email"}}, Where: "deleted_at is null"},
+		}))
+	})
+
+	It("skips the indexes of relations it does not describe", func() {
+		catalog, err := load(`
+			CREATE TABLE users (id integer PRIMARY KEY, email text);
+			CREATE MATERIALIZED VIEW user_emails AS SELECT id, email FROM users;
+			CREATE UNIQUE INDEX user_emails_email_idx ON user_emails (email);
+			CREATE TABLE user_copies AS SELECT * FROM users;
+			CREATE INDEX user_copies_email_idx ON user_copies (email);
+		`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(catalog.Schemas[0].Tables).To(HaveLen(1))
+	})
+
+	When("a statement references an unknown table", func() {
+		It("returns an error", func() {
+			_, err := load(`ALTER TABLE missing ADD COLUMN id integer;`)
+			Expect(err).To(MatchError(ContainSubstring(`table "missing" does not exist`)))
+		})
+	})
+
+	When("an ALTER TABLE IF EXISTS statement references an unknown table", func() {
+		It("skips the statement", func() {
+			catalog, err := load(`
+				CREATE TABLE items (id integer);
+				ALTER TABLE IF EXISTS missing ADD COLUMN code text;
+				ALTER TABLE IF EXISTS items ADD COLUMN code text;
+			`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.GetTable("missing")).To(BeNil())
+			Expect(catalog.GetTable("items").GetColumn("code")).NotTo(BeNil())
+		})
+	})
+
+	When("the SQL text is malformed", func() {
+		It("returns an error", func() {
+			_, err := load(`CREATE TABLE users (name text DEFAULT 'unterminated);`)
+			Expect(err).To(MatchError(ContainSubstring("unterminated string literal")))
+		})
+	})
+
+	When("the path does not exist", func() {
+		It("returns an error", func() {
+			_, err := sqlc.LoadCatalogDDL("postgresql", "public", "./missing")
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+-- Public schema
+CREATE TABLE users (
+    id integer NOT NULL,
+    email varchar(255) NOT NULL,
+    name text,
+    CONSTRAINT users_pkey PRIMARY KEY (id)
+);
+
+COMMENT ON TABLE users IS 'User accounts';
+COMMENT ON COLUMN users.id IS 'Primary key';
+COMMENT ON COLUMN users.email IS 'User email address';
+
+CREATE UNIQUE INDEX idx_users_email ON users (email);
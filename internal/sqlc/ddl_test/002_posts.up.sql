@@ -0,0 +1,19 @@
+CREATE TABLE posts (
+    id integer PRIMARY KEY,
+    user_id integer NOT NULL,
+    title text NOT NULL,
+    content text,
+    legacy_id integer,
+    /* Optional author reference for testing LEFT JOIN */
+    author_id integer
+);
+
+ALTER TABLE posts
+    ADD CONSTRAINT fk_posts_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE posts ADD CONSTRAINT fk_posts_author_id FOREIGN KEY (author_id) REFERENCES users;
+
+CREATE INDEX idx_posts_user_id ON posts (user_id);
+CREATE INDEX idx_posts_title_expr ON posts USING btree (lower(title));
+CREATE INDEX idx_posts_title ON posts (title);
+CREATE INDEX idx_posts_legacy_id ON posts (legacy_id);
@@ -0,0 +1,6 @@
+ALTER TABLE posts DROP COLUMN legacy_id;
+
+CREATE TABLE drafts (id integer PRIMARY KEY, body text);
+CREATE INDEX idx_drafts_body ON drafts (body);
+DROP INDEX idx_drafts_body;
+DROP TABLE IF EXISTS drafts;
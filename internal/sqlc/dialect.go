@@ -0,0 +1,281 @@
+package sqlc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// plain matches identifiers that never need quoting, apart from keywords.
+var plain = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Dialect describes how queries are rendered for a specific database engine.
+// The engine name matches the `engine` field of a sqlc configuration block.
+type Dialect struct {
+	// Engine is the sqlc engine name (postgresql, mysql or sqlite).
+	Engine string
+	// Schema is the default schema for unqualified table names.
+	Schema string
+	// SearchPath reports whether generated files start with a SET search_path statement.
+	SearchPath bool
+	// Returning reports whether INSERT, UPDATE and DELETE support a RETURNING clause.
+	Returning bool
+	// Kinds is the set of sqlc query annotations supported by the engine.
+	Kinds map[string]bool
+	// Keywords is the set of reserved words that must be quoted when used as
+	// identifiers.
+	Keywords map[string]bool
+}
+
+// Dialects holds the supported dialects keyed by sqlc engine name.
+var Dialects = map[string]*Dialect{
+	"postgresql": {
+		Engine:     "postgresql",
+		Schema:     "public",
+		SearchPath: true,
+		Returning:  true,
+		Kinds: map[string]bool{
+			":one":       true,
+			":many":      true,
+			":exec":      true,
+			":execrows":  true,
+			":batchone":  true,
+			":batchmany": true,
+			":batchexec": true,
+			":copyfrom":  true,
+		},
+		Keywords: postgresKeywords,
+	},
+	"mysql": {
+		Engine:     "mysql",
+		Schema:     "public",
+		SearchPath: false,
+		Returning:  false,
+		Kinds: map[string]bool{
+			":one":        true,
+			":many":       true,
+			":exec":       true,
+			":execrows":   true,
+			":execlastid": true,
+			":copyfrom":   true,
+		},
+		Keywords: mysqlKeywords,
+	},
+	"sqlite": {
+		Engine:     "sqlite",
+		Schema:     "main",
+		SearchPath: false,
+		// RETURNING is available since SQLite 3.35
+		Returning: true,
+		Kinds: map[string]bool{
+			":one":        true,
+			":many":       true,
+			":exec":       true,
+			":execrows":   true,
+			":execlastid": true,
+		},
+		Keywords: sqliteKeywords,
+	},
+}
+
+// GetDialect returns the dialect for the given sqlc engine name. An empty
+// engine defaults to PostgreSQL.
+func GetDialect(engine string) (*Dialect, error) {
+	if engine == "" {
+		engine = "postgresql"
+	}
+
+	if dialect, ok := Dialects[engine]; ok {
+		return dialect, nil
+	}
+
+	return nil, fmt.Errorf("unsupported engine %q", engine)
+}
+
+// Supports reports whether the engine can execute queries of the given kind.
+func (x *Dialect) Supports(kind string) bool {
+	return x.Kinds[kind]
+}
+
+// Kind returns the query annotation to use for a query of the given
+// operation (select, insert, upsert, update or delete) and preferred kind.
+// Engines without RETURNING cannot return rows from writes, so `:one` inserts
+// fall back to `:execlastid` and other row-returning writes to `:execrows`.
+func (x *Dialect) Kind(operation, kind string) string {
+	if x.Returning || operation == "select" {
+		return kind
+	}
+
+	switch kind {
+	case ":one":
+		if operation == "insert" {
+			return ":execlastid"
+		}
+		return ":execrows"
+	case ":many":
+		return ":execrows"
+	}
+
+	return kind
+}
+
+// UpdateMask returns the condition that reports whether the named column
+// is listed in the update mask argument.
+func (x *Dialect) UpdateMask(column string) string {
+	switch x.Engine {
+	case "mysql":
+		// MySQL has no arrays; the mask is a comma-separated list of column names.
+		return fmt.Sprintf("FIND_IN_SET('%s', sqlc.arg(update_mask)) > 0", column)
+	case "sqlite":
+		// SQLite has no arrays; each column gets its own boolean flag.
+		return fmt.Sprintf("CAST(sqlc.narg(%s) AS BOOLEAN)", x.Param("update_"+column))
+	default:
+		return fmt.Sprintf("'%s' = any(sqlc.arg(update_mask))", column)
+	}
+}
+
+// UpdateMaskParam returns the argument that UpdateMask binds for the named
+// column.
+func (x *Dialect) UpdateMaskParam(column string) string {
+	if x.Engine == "sqlite" {
+		return x.Param("update_" + column)
+	}
+	return "update_mask"
+}
+
+// Limit returns the LIMIT/OFFSET clause used for offset pagination.
+func (x *Dialect) Limit() string {
+	switch x.Engine {
+	case "mysql":
+		return "LIMIT\n    sqlc.arg(skip), sqlc.arg(take)"
+	case "sqlite":
+		return "LIMIT\n    sqlc.arg(take)\nOFFSET\n    sqlc.arg(skip)"
+	default:
+		return "LIMIT\n    sqlc.narg(take)::int\nOFFSET\n    sqlc.narg(skip)::int"
+	}
+}
+
+// LimitParams returns the arguments of the Limit clause in order.
+func (x *Dialect) LimitParams() []string {
+	if x.Engine == "mysql" {
+		return []string{"skip", "take"}
+	}
+	return []string{"take", "skip"}
+}
+
+// Quote returns the quoted, dot-separated identifier made of the given parts,
+// e.g. "auth"."accounts".
+func (x *Dialect) Quote(parts ...string) string {
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		items = append(items, x.quote(part))
+	}
+
+	return strings.Join(items, ".")
+}
+
+// Ident returns the dot-separated identifier made of the given parts, quoting
+// only the parts that are reserved words or not plain lower-case names, e.g.
+// auth."order". A nil dialect leaves the parts as they are.
+func (x *Dialect) Ident(parts ...string) string {
+	if x == nil {
+		return strings.Join(parts, ".")
+	}
+
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if plain.MatchString(part) && !x.Keywords[part] {
+			items = append(items, part)
+			continue
+		}
+		items = append(items, x.quote(part))
+	}
+
+	return strings.Join(items, ".")
+}
+
+// quote quotes a single identifier.
+func (x *Dialect) quote(name string) string {
+	quote := `"`
+	if x.Engine == "mysql" {
+		quote = "`"
+	}
+	// Quotes inside an identifier are escaped by doubling them
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// Param returns the sqlc argument name for the given column name. The name is
+// converted to snake_case so that the generated Go fields stay clean, e.g.
+// "createdAt" becomes created_at, and quoted when it is a reserved word.
+func (x *Dialect) Param(name string) string {
+	name = paramName(name)
+	if x == nil {
+		return name
+	}
+	return x.Ident(name)
+}
+
+// Now returns the expression for the current timestamp.
+func (x *Dialect) Now() string {
+	switch x.Engine {
+	case "mysql":
+		return "NOW()"
+	case "sqlite":
+		return "CURRENT_TIMESTAMP"
+	default:
+		return "now()"
+	}
+}
+
+// KeysetLimit returns the LIMIT clause used for keyset pagination.
+func (x *Dialect) KeysetLimit() string {
+	switch x.Engine {
+	case "mysql", "sqlite":
+		return "LIMIT\n    sqlc.arg(take)"
+	default:
+		return "LIMIT\n    sqlc.narg(take)::int"
+	}
+}
+
+// OnConflict returns the clause that turns an INSERT into an upsert on the
+// given unique key. The columns are overwritten with the inserted values. When
+// there are none, the first key column is assigned instead so that the
+// existing row is still returned. The predicate of a partial index is part of
+// the conflict target, which would not match the index otherwise.
+func (x *Dialect) OnConflict(key *Index, columns []Column) string {
+	var target []string
+	for _, part := range key.Parts {
+		target = append(target, x.Ident(part.Column))
+	}
+
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, x.Ident(column.Name))
+	}
+
+	if len(names) == 0 {
+		names = target[:1]
+	}
+
+	var items []string
+	for _, column := range names {
+		switch x.Engine {
+		case "mysql":
+			items = append(items, fmt.Sprintf("    %s = VALUES(%s)", column, column))
+		default:
+			items = append(items, fmt.Sprintf("    %s = EXCLUDED.%s", column, column))
+		}
+	}
+
+	switch x.Engine {
+	case "mysql":
+		// MySQL has no conflict target; any unique key triggers the update.
+		return "ON DUPLICATE KEY UPDATE\n" + strings.Join(items, ",\n")
+	default:
+		clause := fmt.Sprintf("ON CONFLICT (%s)", strings.Join(target, ", "))
+		if key.Where != "" {
+			clause += " WHERE " + key.Where
+		}
+		return clause + " DO UPDATE SET\n" + strings.Join(items, ",\n")
+	}
+}
@@ -0,0 +1,177 @@
+package sqlc_test
+
+import (
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dialect", func() {
+	Describe("GetDialect", func() {
+		It("returns the dialect for a known engine", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Engine).To(Equal("mysql"))
+		})
+
+		It("defaults to postgresql when the engine is empty", func() {
+			dialect, err := sqlc.GetDialect("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Engine).To(Equal("postgresql"))
+		})
+
+		When("the engine is not supported", func() {
+			It("returns an error", func() {
+				dialect, err := sqlc.GetDialect("oracle")
+				Expect(err).To(MatchError(ContainSubstring(`unsupported engine "oracle"`)))
+				Expect(dialect).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Kind", func() {
+		It("keeps the kind when the engine supports RETURNING", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Kind("insert", ":one")).To(Equal(":one"))
+			Expect(dialect.Kind("delete", ":many")).To(Equal(":many"))
+		})
+
+		It("maps row-returning writes when the engine lacks RETURNING", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Kind("select", ":one")).To(Equal(":one"))
+			Expect(dialect.Kind("insert", ":one")).To(Equal(":execlastid"))
+			Expect(dialect.Kind("update", ":one")).To(Equal(":execrows"))
+			Expect(dialect.Kind("delete", ":many")).To(Equal(":execrows"))
+			Expect(dialect.Kind("delete", ":exec")).To(Equal(":exec"))
+		})
+	})
+
+	Describe("Supports", func() {
+		It("reports batch kinds as unsupported on mysql", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Supports(":one")).To(BeTrue())
+			Expect(dialect.Supports(":batchone")).To(BeFalse())
+		})
+
+		It("reports copyfrom as unsupported on sqlite", func() {
+			dialect, err := sqlc.GetDialect("sqlite")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Supports(":execlastid")).To(BeTrue())
+			Expect(dialect.Supports(":copyfrom")).To(BeFalse())
+		})
+	})
+
+	Describe("UpdateMask", func() {
+		It("uses an array membership test on postgresql", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.UpdateMask("name")).To(Equal("'name' = any(sqlc.arg(update_mask))"))
+		})
+
+		It("uses FIND_IN_SET on mysql", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.UpdateMask("name")).To(Equal("FIND_IN_SET('name', sqlc.arg(update_mask)) > 0"))
+		})
+
+		It("uses one boolean flag per column on sqlite", func() {
+			dialect, err := sqlc.GetDialect("sqlite")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.UpdateMask("name")).To(Equal("CAST(sqlc.narg(update_name) AS BOOLEAN)"))
+		})
+	})
+
+	Describe("OnConflict", func() {
+		key := &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "email"}}}
+		columns := []sqlc.Column{{Name: "name"}, {Name: "bio"}}
+
+		It("uses ON CONFLICT on postgresql", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.OnConflict(key, columns)).To(Equal("ON CONFLICT (email) DO UPDATE SET\n    name = EXCLUDED.name,\n    bio = EXCLUDED.bio"))
+		})
+
+		It("uses ON DUPLICATE KEY UPDATE on mysql", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.OnConflict(key, columns)).To(Equal("ON DUPLICATE KEY UPDATE\n    name = VALUES(name),\n    bio = VALUES(bio)"))
+		})
+
+		It("assigns the key column when there is nothing else to update", func() {
+			dialect, err := sqlc.GetDialect("sqlite")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.OnConflict(key, nil)).To(Equal("ON CONFLICT (email) DO UPDATE SET\n    email = EXCLUDED.email"))
+		})
+
+		It("targets the predicate of a partial index", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+
+			partial := &sqlc.Index{Parts: key.Parts, Where: "deleted_at is null"}
+			Expect(dialect.OnConflict(partial, columns[:1])).To(Equal("ON CONFLICT (email) WHERE deleted_at is null DO UPDATE SET\n    name = EXCLUDED.name"))
+		})
+	})
+
+	Describe("Quote", func() {
+		It("uses double quotes on postgresql", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Quote("auth", "accounts")).To(Equal(`"auth"."accounts"`))
+		})
+
+		It("uses backticks on mysql", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Quote("auth", "accounts")).To(Equal("`auth`.`accounts`"))
+		})
+
+		It("escapes embedded quotes", func() {
+			dialect, err := sqlc.GetDialect("sqlite")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Quote(`odd"name`)).To(Equal(`"odd""name"`))
+		})
+	})
+
+	Describe("Ident", func() {
+		It("keeps plain names as they are", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Ident("auth", "accounts")).To(Equal("auth.accounts"))
+		})
+
+		It("quotes reserved words and mixed-case names", func() {
+			dialect, err := sqlc.GetDialect("postgresql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Ident("order")).To(Equal(`"order"`))
+			Expect(dialect.Ident("createdAt")).To(Equal(`"createdAt"`))
+		})
+
+		It("uses the reserved words of the engine", func() {
+			dialect, err := sqlc.GetDialect("mysql")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Ident("key")).To(Equal("`key`"))
+			Expect(dialect.Ident("user")).To(Equal("user"))
+		})
+	})
+
+	Describe("Param", func() {
+		dialect, err := sqlc.GetDialect("postgresql")
+
+		It("converts names to snake_case", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Param("createdAt")).To(Equal("created_at"))
+			Expect(dialect.Param("HTTPStatus")).To(Equal("http_status"))
+			Expect(dialect.Param("Unit Price")).To(Equal("unit_price"))
+			Expect(dialect.Param("2fa")).To(Equal("col_2fa"))
+		})
+
+		It("quotes reserved words", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dialect.Param("user")).To(Equal(`"user"`))
+		})
+	})
+})
@@ -2,71 +2,262 @@ package sqlc
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
-	"github.com/go-openapi/inflect"
 	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc/template"
 )
 
 // blank matches two or more consecutive blank lines.
 var blank = regexp.MustCompile(`\n{3,}`)
 
-func init() {
-	inflect.AddSingular("quota", "quota")
-	inflect.AddPlural("quota", "quotas")
-}
+// Header is the first line of every generated file. Only files that start
+// with it are ever pruned (see Generator.Generate).
+const Header = "-- Code generated by sqlc-gen-queries. DO NOT EDIT.\n"
 
 // Generator generates an SQL queries
 type Generator struct {
 	Config  *Config
 	Catalog *Catalog
+	// TemplateDir is the directory of the templates used by the SQL blocks
+	// that do not set the templates option (see template.OpenDir).
+	TemplateDir string
+	// NoPrune keeps the generated files that are no longer rendered.
+	NoPrune bool
+	// Writer writes the rendered files. Files are written to disk when nil
+	// (see DirWriter).
+	Writer Writer
+	// Strict fails on include and exclude entries that match no table or
+	// query instead of logging a warning.
+	Strict bool
+}
+
+// File represents a rendered query file.
+type File struct {
+	// Path is the file path, relative to the working directory.
+	Path string
+	// Content is the rendered SQL.
+	Content []byte
+	// Tables lists the tables rendered into the file, in order. Their queries
+	// are limited to the section of the file.
+	Tables []*TableQueries
+	// Parts holds the SQL rendered for each of the Tables, in the same order.
+	Parts [][]byte
 }
 
-// Generate generates the queries based on the configuration.
+// Filter returns the file limited to the parts of the table, given by plain
+// or schema-qualified name, or nil when the table is not rendered into it.
+func (x *File) Filter(name string) *File {
+	file := &File{Path: x.Path, Content: []byte(Header)}
+	for i, table := range x.Tables {
+		if name == table.Table.Name || name == table.Schema+"."+table.Table.Name {
+			file.Content = append(file.Content, x.Parts[i]...)
+			file.Tables = append(file.Tables, table)
+			file.Parts = append(file.Parts, x.Parts[i])
+		}
+	}
+
+	if len(file.Tables) == 0 {
+		return nil
+	}
+
+	file.Content = blank.ReplaceAll(file.Content, []byte("\n\n"))
+	return file
+}
+
+// Generate generates the queries based on the configuration and writes them
+// to the output directory of each SQL block (see SQL.GetOut) with the Writer.
+// Unless NoPrune is set, it then removes the generated files that were not
+// rendered this time, such as those of dropped or excluded tables.
 func (x *Generator) Generate() error {
+	return Generators{x}.Generate()
+}
+
+// writer returns the Writer, DirWriter by default.
+func (x *Generator) writer() Writer {
+	if x.Writer == nil {
+		return &DirWriter{}
+	}
+	return x.Writer
+}
+
+// Generators generate the SQL blocks that each have a catalog of their own,
+// such as the schemas parsed from DDL files. Blocks may share an output
+// directory, so a file is only stale when no generator renders it.
+type Generators []*Generator
+
+// Generate writes the files of every generator, then removes the stale
+// files of those without NoPrune (see Generator.Generate).
+func (x Generators) Generate() error {
+	var files []*File
+	for _, generator := range x {
+		rendered, err := generator.Render()
+		if err != nil {
+			return err
+		}
+
+		writer := generator.writer()
+		for _, file := range rendered {
+			if err := writer.WriteFile(file); err != nil {
+				return err
+			}
+		}
+
+		files = append(files, rendered...)
+	}
+
+	// Generators sharing an output directory find the same stale files
+	removed := make(map[string]bool)
+	for _, generator := range x {
+		if generator.NoPrune {
+			continue
+		}
+
+		stale, err := generator.stale(files)
+		if err != nil {
+			return err
+		}
+
+		writer := generator.writer()
+		for _, path := range stale {
+			if removed[path] {
+				continue
+			}
+			if err := writer.RemoveFile(path); err != nil {
+				return err
+			}
+			removed[path] = true
+		}
+	}
+
+	return nil
+}
+
+// stale returns the generated files that are not among the rendered files.
+// It looks in the output directory of each SQL block and in every directory
+// the rendered files are written to, but never in their subdirectories, so
+// the output of another SQL block nested below is left alone.
+func (x *Generator) stale(files []*File) ([]string, error) {
+	rendered := make(map[string]bool, len(files))
+	dirs := make(map[string]bool)
+	for _, config := range x.Config.SQL {
+		dirs[filepath.Clean(config.GetOut())] = true
+	}
+	for _, file := range files {
+		rendered[filepath.Clean(file.Path)] = true
+		dirs[filepath.Dir(filepath.Clean(file.Path))] = true
+	}
+
+	var stale []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || filepath.Ext(path) != ".sql" || rendered[path] {
+				continue
+			}
+
+			ok, err := isGenerated(path)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				stale = append(stale, path)
+			}
+		}
+	}
+
+	slices.Sort(stale)
+	return stale, nil
+}
+
+// isGenerated reports whether the file at path starts with the Header.
+func isGenerated(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	data := make([]byte, len(Header))
+	if _, err := io.ReadFull(file, data); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return string(data) == Header, nil
+}
+
+// Render renders the queries based on the configuration without touching
+// the filesystem.
+func (x *Generator) Render() ([]*File, error) {
 	// Context holds data for template execution
 	type Context struct {
 		Engine       string
+		Dialect      *Dialect
 		Schema       string
 		Table        *Table
-		QueryInclude map[string]bool
-		QueryExclude map[string]bool
+		Key          *Index
+		Ident        string
+		Qualify      bool
+		Name         string
+		Section      string
+		Continued    bool
+		SoftDelete   *Column
+		Queries      []*Query
+		Skipped      []*Query
+		CreatedAt    map[string]bool
+		UpdatedAt    map[string]bool
+		Readonly     map[string]bool
+		Exclude      map[string]bool
+		Joins        map[string]*TableConfig
+		QueryInclude Patterns
+		QueryExclude Patterns
 	}
 
+	// naming inflects the table names of the SQL block being rendered
+	var naming *Naming
+
 	opts := map[string]any{
+		// Inflection follows the naming option of the block
+		"singular": func(word string) string { return naming.Singular(word) },
+		"plural":   func(word string) string { return naming.Plural(word) },
 		// Table Functions
-		"table_ref": func(fk ForeignKey) string {
-			column := fk.Columns[0]
-			// If the column name starts with the referenced table name, use that as the role
-			if strings.HasPrefix(column, fk.References.Table+"_") {
-				return fk.References.Table
-			}
-
-			suffixes := []string{"_id", "_fk", "_ref", "_key"}
-			// Remove common FK suffixes
-			for _, suffix := range suffixes {
-				column = strings.TrimSuffix(column, suffix)
-			}
-
-			return column
-		},
+		"table_ref": tableRole,
 		"table_name": func(table string, kind string) string {
 			switch kind {
 			case "many":
-				return inflect.Camelize(inflect.Pluralize(table))
+				return naming.tableName(table, true)
 			case "one":
-				return inflect.Camelize(inflect.Singularize(table))
+				return naming.tableName(table, false)
 			}
 			return ""
 		},
-		"table_join": func(table Table, fk ForeignKey) string {
-			// We assume the foreign key references another table in the same catalog
-			// Otherwise it will return nil and likely panic
-			tableRef := x.Catalog.GetTable(fk.References.Table)
+		"table_join": func(ctx Context, fk ForeignKey) (string, error) {
+			table := *ctx.Table
+			schema, tableRef, err := x.Catalog.GetReferencedTable(ctx.Schema, fk)
+			if err != nil {
+				return "", err
+			}
 
 			jtype := "INNER JOIN"
 			// Determine join type: LEFT JOIN if any FK column is nullable
@@ -77,6 +268,18 @@ func (x *Generator) Generate() error {
 				}
 			}
 
+			// Qualify the referenced table when it lives in another schema
+			var qualifier string
+			if schema != ctx.Schema {
+				qualifier = schema
+			}
+
+			// Quote and qualify every identifier when requested
+			var owner string
+			if ctx.Qualify {
+				owner, qualifier = ctx.Schema, schema
+			}
+
 			condition := &CompositeCondition{Operator: "AND"}
 			// Build the join condition
 			for i := range fk.Columns {
@@ -85,128 +288,557 @@ func (x *Generator) Generate() error {
 				// We assume the columns exist; otherwise it will likely panic
 				condition.AddColumnRef(
 					&ColumnRef{
-						Table: &table,
-						Name:  column,
+						Schema:  owner,
+						Table:   &table,
+						Name:    column,
+						Dialect: ctx.Dialect,
+						Quoted:  ctx.Qualify,
 					},
 					&ColumnRef{
-						Table: tableRef,
-						Name:  columnRef,
+						Schema:  qualifier,
+						Table:   tableRef,
+						Name:    columnRef,
+						Dialect: ctx.Dialect,
+						Quoted:  ctx.Qualify,
 					},
 				)
 			}
 
-			return fmt.Sprintf("%s %s ON %s", jtype, tableRef.Name, condition.String())
+			name := ctx.Dialect.Ident(tableRef.Name)
+			switch {
+			case ctx.Qualify:
+				name = ctx.Dialect.Quote(schema, tableRef.Name)
+			case qualifier != "":
+				name = ctx.Dialect.Ident(qualifier, tableRef.Name)
+			}
+
+			return fmt.Sprintf("%s %s ON %s", jtype, name, condition.String()), nil
 		},
-		"table_embed": func(table string) string {
-			return fmt.Sprintf("sqlc.embed(%s)", table)
+		// Template blocks rendered once per key receive the key in the context
+		"with_key": func(ctx Context, key *Index) Context {
+			ctx.Key = key
+			return ctx
+		},
+		// Join queries embed the table, or the table referenced by the
+		// foreign key, unless columns are left out of its results: sqlc.embed
+		// selects every column, so those are selected by name instead
+		"table_embed": func(ctx Context, arg any) (string, error) {
+			var (
+				schema  = ctx.Schema
+				table   = ctx.Table
+				exclude = ctx.Exclude
+			)
+			switch arg := arg.(type) {
+			case ForeignKey:
+				var err error
+				if schema, table, err = x.Catalog.GetReferencedTable(ctx.Schema, arg); err != nil {
+					return "", err
+				}
+				exclude = nil
+				if config := ctx.Joins[schema+"."+table.Name]; config != nil {
+					exclude = config.ExcludeColumns
+				}
+			default:
+				if name := fmt.Sprint(arg); name != table.Name {
+					return fmt.Sprintf("sqlc.embed(%s)", ctx.Dialect.Ident(name)), nil
+				}
+			}
+
+			columns := table.GetSelectColumns(exclude)
+			if len(columns) == len(table.Columns) {
+				return fmt.Sprintf("sqlc.embed(%s)", ctx.Dialect.Ident(table.Name)), nil
+			}
+
+			// Qualify the columns the way table_join does
+			var qualifier string
+			if schema != ctx.Schema || ctx.Qualify {
+				qualifier = schema
+			}
+
+			names := make([]string, 0, len(columns))
+			for _, column := range columns {
+				ref := &ColumnRef{
+					Schema:  qualifier,
+					Table:   table,
+					Name:    column.Name,
+					Dialect: ctx.Dialect,
+					Quoted:  ctx.Qualify,
+				}
+				names = append(names, ref.String())
+			}
+			return strings.Join(names, ", "), nil
+		},
+		// Rows are selected and returned with *, unless columns are left
+		// out of the results (see ColumnOptions)
+		"table_columns": func(ctx Context) string {
+			columns := ctx.Table.GetSelectColumns(ctx.Exclude)
+			if len(columns) == len(ctx.Table.Columns) {
+				return "*"
+			}
+			names := make([]string, 0, len(columns))
+			for _, column := range columns {
+				names = append(names, ctx.Dialect.Ident(column.Name))
+			}
+			return strings.Join(names, ", ")
 		},
 		// Query Functions
-		"query_condition": func(table Table, index *Index) string {
-			condition := &CompositeCondition{Operator: "AND"}
+		"query_condition": func(ctx Context, index *Index) string {
+			condition := &CompositeCondition{Operator: "AND", Dialect: ctx.Dialect}
 			// Build the condition clause
 			for _, part := range index.Parts {
-				if column := table.GetColumn(part.Column); column != nil {
+				if column := ctx.Table.GetColumn(part.Column); column != nil {
 					condition.AddColumn(column)
 				}
 			}
 			return condition.String()
 		},
-		"query_argument": func(column Column) string {
+		"query_argument": func(ctx Context, column Column) string {
 			argument := Argument{
-				Column: &column,
+				Column:  &column,
+				Dialect: ctx.Dialect,
 			}
 			return argument.String()
 		},
-		"query_index": func(index *Index) string {
-			// Don't add suffix for primary key lookups
-			if index.Name == "primary key" {
-				return ""
+		// Timestamp columns are set by the database rather than bound
+		"query_value": func(ctx Context, column Column) string {
+			if ctx.CreatedAt[column.Name] || ctx.UpdatedAt[column.Name] {
+				return ctx.Dialect.Now()
+			}
+			// Fall back to the column default when no value is given
+			if column.Default != "" {
+				return fmt.Sprintf("COALESCE(sqlc.narg(%s), %s)", ctx.Dialect.Param(column.Name), column.Default)
+			}
+			argument := Argument{
+				Column:  &column,
+				Dialect: ctx.Dialect,
+			}
+			return argument.String()
+		},
+		"update_columns": func(ctx Context, columns []Column) []Column {
+			return updateColumns(ctx.CreatedAt, ctx.Readonly, columns)
+		},
+		// Upserts receive their key in the context and keep its columns
+		"insert_columns": func(ctx Context, columns []Column) []Column {
+			return insertColumns(ctx.Readonly, ctx.Key, columns)
+		},
+		"is_updated_at": func(ctx Context, column Column) bool {
+			return ctx.UpdatedAt[column.Name]
+		},
+		"query_index": indexSuffix,
+		// Query lookup: the selected query of the given variant for the key
+		// and foreign key, or nil when it does not render
+		"query_lookup": func(ctx Context, variant string, args ...any) *Query {
+			var (
+				key *Index
+				ref *ForeignKey
+			)
+			for _, arg := range args {
+				switch arg := arg.(type) {
+				case *Index:
+					key = arg
+				case ForeignKey:
+					ref = &arg
+				}
 			}
 
-			var items []string
-			// Build the suffix based on index parts
-			for _, part := range index.Parts {
-				items = append(items, inflect.Camelize(part.Column))
+			for _, query := range ctx.Queries {
+				if ctx.Section != "" && query.Section() != ctx.Section {
+					continue
+				}
+				if query.Matches(variant, key, ref) {
+					return query
+				}
 			}
-			return "By" + strings.Join(items, "And")
+			return nil
 		},
 		// Pagination functions
-		"query_order": func(table Table) string {
+		"query_order": func(ctx Context) string {
+			table := ctx.Table
 			if table.PrimaryKey == nil {
 				return ""
 			}
 			cols := make([]string, 0, len(table.PrimaryKey.Parts))
 			for _, p := range table.PrimaryKey.Parts {
-				cols = append(cols, p.Column)
+				column := ctx.Dialect.Ident(p.Column)
+				if p.Desc {
+					cols = append(cols, column+" DESC")
+					continue
+				}
+				cols = append(cols, column)
 			}
 			return strings.Join(cols, ", ")
 		},
+		"query_cursor": func(ctx Context) string {
+			table := ctx.Table
+			if table.PrimaryKey == nil || table.PrimaryKey.HasExpr() {
+				return ""
+			}
+			condition := &CursorCondition{Index: table.PrimaryKey, Dialect: ctx.Dialect}
+			return condition.String()
+		},
 		// Foreign key index check
 		"is_fk_index": func(table Table, index *Index) bool {
 			return table.IsForeignKeyIndex(index)
 		},
-		// Query selection: a query renders when it belongs to the default set
-		// or is explicitly included, and never when excluded (exclude wins).
-		"should_generate": func(ctx Context, queryName string, isDefault bool) bool {
-			if ctx.QueryExclude[queryName] {
+		// Query selection for custom templates that build their own queries
+		// (see selectQueries)
+		"should_generate": func(ctx Context, queryName, queryKind string, isDefault bool) bool {
+			if ctx.Section != "" && querySection(queryName) != ctx.Section {
 				return false
 			}
-			return isDefault || ctx.QueryInclude[queryName]
+			queries := slices.Concat(ctx.Queries, ctx.Skipped)
+			return querySelected(ctx.Dialect, ctx.QueryInclude, ctx.QueryExclude, queries, queryName, queryKind, isDefault)
 		},
 	}
 
-	// Open the template file
-	template, err := template.Open("template.sql.tmpl", opts)
-	if err != nil {
-		return err
-	}
+	var files []*File
 
 	for _, config := range x.Config.SQL {
-		if err := os.MkdirAll(config.Queries, os.ModePerm); err != nil {
-			return err
+		dialect, err := GetDialect(config.Engine)
+		if err != nil {
+			return nil, err
 		}
 
-		queryInclude := config.GetQueryIncludeSet()
-		queryExclude := config.GetQueryExcludeSet()
-		include := config.GetIncludeSet()
-		exclude := config.GetExcludeSet()
+		options := config.GetOptions()
 
-		for _, schema := range x.Catalog.Schemas {
-			for _, table := range schema.Tables {
-				if !tableSelected(include, exclude, schema.Name, table.Name) {
-					continue
+		layout, err := GetLayout(options.Layout)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, err := layout.ParseFileName(options.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("parsing the file name pattern: %w", err)
+		}
+
+		// Open the template file, overridden by the templates of the block
+		template, err := template.OpenDir(cmp.Or(options.Templates, x.TemplateDir), "template.sql.tmpl", opts)
+		if err != nil {
+			return nil, err
+		}
+
+		tables, err := x.Queries(&config)
+		if err != nil {
+			return nil, err
+		}
+
+		// Name the tables in the template the way their queries are named
+		naming = newNaming(options.Naming)
+		if _, err := tableNames(x.Catalog, dialect.Schema, &config, naming); err != nil {
+			return nil, err
+		}
+
+		out := config.GetOut()
+
+		// Files shared by several tables are rendered in catalog order
+		index := make(map[string]*File)
+		owners := make(map[string]string)
+		shared := !perTable(pattern)
+
+		for _, item := range tables {
+			table := item.Table
+			qualified := item.Schema + "." + table.Name
+
+			for _, section := range layout.Sections {
+				name := &FileName{
+					Engine:  config.Engine,
+					Schema:  item.Schema,
+					Table:   table.Name,
+					Name:    item.Name,
+					Section: section,
 				}
 
-				file, err := os.Create(
-					filepath.Join(config.Queries,
-						fmt.Sprintf("%s.sql", table.Name)),
-				)
-				if err != nil {
-					return err
+				var path bytes.Buffer
+				if err := pattern.Execute(&path, name); err != nil {
+					return nil, fmt.Errorf("rendering the file name: %w", err)
+				}
+
+				// Never merge two tables into a file meant for one
+				if owner, ok := owners[path.String()]; ok && owner != qualified && !shared {
+					return nil, fmt.Errorf("tables %s and %s are both written to %s", owner, qualified, path.String())
+				}
+				owners[path.String()] = qualified
+
+				file, ok := index[path.String()]
+				if !ok {
+					file = &File{Path: filepath.Join(out, path.String()), Content: []byte(Header)}
+					index[path.String()] = file
+					files = append(files, file)
 				}
-				//nolint:all
-				defer file.Close()
 
 				ctx := Context{
 					Engine:       config.Engine,
-					Schema:       schema.Name,
-					Table:        &table,
-					QueryInclude: queryInclude,
-					QueryExclude: queryExclude,
+					Dialect:      dialect,
+					Schema:       item.Schema,
+					Table:        table,
+					Ident:        dialect.Ident(table.Name),
+					Qualify:      options.Qualify,
+					Name:         item.Name,
+					Section:      section,
+					Continued:    ok,
+					SoftDelete:   item.SoftDelete,
+					Queries:      item.Queries,
+					Skipped:      item.Skipped,
+					CreatedAt:    item.Config.CreatedAt,
+					UpdatedAt:    item.Config.UpdatedAt,
+					Readonly:     item.Config.ReadonlyColumns,
+					Exclude:      item.Config.ExcludeColumns,
+					Joins:        item.Joins,
+					QueryInclude: item.Config.QueryInclude,
+					QueryExclude: item.Config.QueryExclude,
+				}
+				if options.Qualify {
+					ctx.Ident = dialect.Quote(item.Schema, table.Name)
 				}
-				// Execute template into buffer, then squeeze blank lines
+				// Execute template into the file content
 				var buffer bytes.Buffer
 				if err := template.Execute(&buffer, ctx); err != nil {
-					return err
+					return nil, err
+				}
+
+				file.Content = append(file.Content, buffer.Bytes()...)
+				file.Tables = append(file.Tables, item.section(section))
+				file.Parts = append(file.Parts, buffer.Bytes())
+			}
+		}
+	}
+
+	// Squeeze blank lines, including those between the parts of shared files
+	for _, file := range files {
+		file.Content = blank.ReplaceAll(file.Content, []byte("\n\n"))
+	}
+
+	return files, nil
+}
+
+// TableQueries holds the queries generated for a table.
+type TableQueries struct {
+	// Schema is the schema of the table.
+	Schema string
+	// Table is the table the queries are generated for.
+	Table *Table
+	// Name is the name the queries are named after (see SchemaPrefix).
+	Name string
+	// SoftDelete is the soft-delete column of the table, if any.
+	SoftDelete *Column
+	// Queries lists the selected queries in the order they are rendered.
+	Queries []*Query
+	// Skipped lists the queries that are not rendered, in the same order.
+	Skipped []*Query
+	// Config holds the options of the table, overrides included.
+	Config *TableConfig
+	// Joins holds the options of the tables referenced by the foreign keys,
+	// by schema-qualified name.
+	Joins map[string]*TableConfig
+}
+
+// section returns the table with the queries of the given section (see
+// Layout.Sections), or the table itself when the section is empty.
+func (x *TableQueries) section(section string) *TableQueries {
+	if section == "" {
+		return x
+	}
+
+	filter := func(queries []*Query) []*Query {
+		var items []*Query
+		for _, query := range queries {
+			if query.Section() == section {
+				items = append(items, query)
+			}
+		}
+		return items
+	}
+
+	item := *x
+	item.Queries = filter(x.Queries)
+	item.Skipped = filter(x.Skipped)
+	return &item
+}
+
+// Queries builds the queries of the tables selected by the SQL block, with
+// the query selection options applied, without rendering any SQL. It fails
+// when two queries share a name. Include and exclude entries that match no
+// table or query are logged with the closest name as a suggestion, or make
+// it fail when Strict is set.
+func (x *Generator) Queries(config *SQL) ([]*TableQueries, error) {
+	dialect, err := GetDialect(config.Engine)
+	if err != nil {
+		return nil, err
+	}
+
+	options := config.GetOptions()
+	queryInclude := config.GetQueryIncludeSet()
+	queryExclude := config.GetQueryExcludeSet()
+	include := config.GetIncludeSet()
+	exclude := config.GetExcludeSet()
+
+	patterns := map[string]Patterns{
+		"tables.include":  include,
+		"tables.exclude":  exclude,
+		"queries.include": queryInclude,
+		"queries.exclude": queryExclude,
+	}
+	for option, set := range patterns {
+		if err := set.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", option, err)
+		}
+	}
+
+	overrides := newPatterns(slices.Collect(maps.Keys(options.Overrides)))
+	patterns["overrides"] = overrides
+	for key, override := range options.Overrides {
+		for option, entries := range map[string][]string{
+			"queries.include": override.Queries.Include,
+			"queries.exclude": override.Queries.Exclude,
+		} {
+			if err := newPatterns(entries).Validate(); err != nil {
+				return nil, fmt.Errorf("overrides.%s.%s: %w", key, option, err)
+			}
+		}
+	}
+	if err := overrides.Validate(); err != nil {
+		return nil, fmt.Errorf("overrides: %w", err)
+	}
+
+	naming := newNaming(options.Naming)
+	names, err := tableNames(x.Catalog, dialect.Schema, config, naming)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*TableQueries
+	// Query names must be unique across the tables of the block
+	owners := make(map[string]string)
+	// Names the patterns are matched against, to report those matching nothing
+	var (
+		tableList []string
+		queryList []*Query
+	)
+	// Queries of the tables each override applies to
+	overrideQueries := make(map[string][]*Query)
+
+	// Resolve the overrides once per table, joined tables included
+	configs := make(map[string]*TableConfig)
+	for _, schema := range x.Catalog.Schemas {
+		for _, table := range schema.Tables {
+			configs[schema.Name+"."+table.Name] = config.GetTableConfig(schema.Name, table.Name)
+		}
+	}
+
+	for _, schema := range x.Catalog.Schemas {
+		for _, table := range schema.Tables {
+			tableList = append(tableList, table.Name, schema.Name+"."+table.Name)
+			if !tableSelected(include, exclude, schema.Name, table.Name) {
+				continue
+			}
+
+			qualified := schema.Name + "." + table.Name
+			tableConfig := configs[qualified]
+
+			item := &TableQueries{
+				Schema: schema.Name,
+				Table:  &table,
+				Name:   names[qualified],
+				Config: tableConfig,
+				Joins:  make(map[string]*TableConfig),
+			}
+			for _, fk := range table.ForeignKeys {
+				// Unresolved references fail when the join is rendered
+				if ref, tableRef, err := x.Catalog.GetReferencedTable(schema.Name, fk); err == nil {
+					item.Joins[ref+"."+tableRef.Name] = configs[ref+"."+tableRef.Name]
 				}
+			}
+			// Tables without the soft-delete column keep hard deletes
+			if name := tableConfig.SoftDelete; name != "" {
+				item.SoftDelete = item.Table.GetColumn(name)
+			}
 
-				data := blank.ReplaceAll(buffer.Bytes(), []byte("\n\n"))
-				if _, err := file.Write(data); err != nil {
-					return err
+			builder := &queryBuilder{
+				Dialect:    dialect,
+				Naming:     naming,
+				Table:      item.Table,
+				Name:       item.Name,
+				SoftDelete: item.SoftDelete,
+				CreatedAt:  tableConfig.CreatedAt,
+				UpdatedAt:  tableConfig.UpdatedAt,
+				Readonly:   tableConfig.ReadonlyColumns,
+			}
+
+			item.Queries, item.Skipped = selectQueries(dialect, builder.Build(), tableConfig.QueryInclude, tableConfig.QueryExclude)
+
+			queryList = append(queryList, item.Queries...)
+			queryList = append(queryList, item.Skipped...)
+			for key := range options.Overrides {
+				if (Patterns{key: true}).Match(table.Name, qualified) {
+					overrideQueries[key] = slices.Concat(overrideQueries[key], item.Queries, item.Skipped)
 				}
 			}
+
+			for _, query := range item.Queries {
+				if owner, ok := owners[query.Name]; ok {
+					return nil, fmt.Errorf("query %s is generated for both %s and %s", query.Name, owner, qualified)
+				}
+				owners[query.Name] = qualified
+			}
+
+			tables = append(tables, item)
 		}
 	}
 
-	return nil
+	// Query entries are matched against the queries of the tables they
+	// apply to; overrides of no table are reported by their key alone
+	queryLists := map[string][]*Query{
+		"queries.include": queryList,
+		"queries.exclude": queryList,
+	}
+	for key, queries := range overrideQueries {
+		override := options.Overrides[key]
+		patterns["overrides."+key+".queries.include"] = newPatterns(override.Queries.Include)
+		patterns["overrides."+key+".queries.exclude"] = newPatterns(override.Queries.Exclude)
+		queryLists["overrides."+key+".queries.include"] = queries
+		queryLists["overrides."+key+".queries.exclude"] = queries
+	}
+
+	var errs []error
+	// Presets are suggested along with the query names
+	queryNames := slices.Sorted(maps.Keys(QueryPresets))
+	for _, query := range queryList {
+		queryNames = append(queryNames, query.Name)
+	}
+
+	for _, option := range slices.Sorted(maps.Keys(patterns)) {
+		list, unmatched := tableList, patterns[option].Unmatched(tableList)
+		if queries, ok := queryLists[option]; ok {
+			list, unmatched = queryNames, patterns[option].UnmatchedQueries(queries)
+		}
+
+		for _, entry := range unmatched {
+			suggestion := suggest(entry, list)
+
+			if x.Strict {
+				err := fmt.Errorf("%s: %q matches nothing", option, entry)
+				if suggestion != "" {
+					err = fmt.Errorf("%w, did you mean %q?", err, suggestion)
+				}
+				errs = append(errs, err)
+				continue
+			}
+
+			attrs := []any{
+				slog.String("option", option),
+				slog.String("pattern", entry),
+				slog.String("engine", config.Engine),
+			}
+			if suggestion != "" {
+				attrs = append(attrs, slog.String("did_you_mean", suggestion))
+			}
+			slog.Warn("The pattern matches nothing", attrs...)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
 }
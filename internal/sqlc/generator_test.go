@@ -1,8 +1,11 @@
 package sqlc_test
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
 
@@ -130,9 +133,12 @@ var _ = Describe("Generator", func() {
 
 				// Default List queries are present
 				Expect(string(content)).To(ContainSubstring("name: ListUsers :many"))
+				Expect(string(content)).To(ContainSubstring("name: CountUsers :one"))
+				Expect(string(content)).To(ContainSubstring("name: ExistsUser :one"))
 
 				// Opt-in queries are not present without config
 				Expect(string(content)).NotTo(ContainSubstring("name: CopyUsers :copyfrom"))
+				Expect(string(content)).NotTo(ContainSubstring("name: ExistsUserByEmail :one"))
 			}
 
 			// Verify posts.sql FK-based list queries
@@ -143,8 +149,34 @@ var _ = Describe("Generator", func() {
 
 				// FK-matching index list query is present by default
 				Expect(string(content)).To(ContainSubstring("name: ListPostsByUserId :many"))
+				Expect(string(content)).To(ContainSubstring("name: CountPostsByUserId :one"))
 				// Non-FK index list query is not present without opt-in
 				Expect(string(content)).NotTo(ContainSubstring("name: ListPostsByTitle :many"))
+				Expect(string(content)).NotTo(ContainSubstring("name: CountPostsByTitle :one"))
+			}
+		})
+
+		It("generates count and exists queries that match their list and get queries", func() {
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin: "gen-queries",
+					Options: sqlc.CodegenOptions{
+						Queries: sqlc.QueryOptions{Include: []string{"ExistsUserByEmail"}},
+					},
+				},
+			}
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			for _, config := range generator.Config.SQL {
+				content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SELECT EXISTS (\n    SELECT\n        1\n    FROM\n        users\n    WHERE\n        email = sqlc.arg(email)\n);"))
+				Expect(string(content)).To(ContainSubstring("SELECT\n    count(*)\nFROM\n    users\nWHERE\n    /* query.where AND */ TRUE;"))
+
+				content, err = os.ReadFile(filepath.Join(config.Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SELECT\n    count(*)\nFROM\n    posts\nWHERE\n    /* query.where AND */ user_id = sqlc.arg(user_id);"))
 			}
 		})
 
@@ -176,6 +208,596 @@ var _ = Describe("Generator", func() {
 			}
 		})
 
+		It("adds keyset pagination queries when included", func() {
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin: "gen-queries",
+					Options: sqlc.CodegenOptions{
+						Queries: sqlc.QueryOptions{Include: []string{"ListUsersAfter", "ListPostsByUserIdAfter"}},
+					},
+				},
+			}
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			for _, config := range generator.Config.SQL {
+				content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: ListUsersAfter :many"))
+				Expect(string(content)).To(ContainSubstring("/* query.where AND */ id > sqlc.arg(after_id)\nORDER BY\n    id\nLIMIT\n    sqlc.narg(take)::int;"))
+
+				content, err = os.ReadFile(filepath.Join(config.Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: ListPostsByUserIdAfter :many"))
+				Expect(string(content)).To(ContainSubstring("/* query.where AND */ user_id = sqlc.arg(user_id) AND id > sqlc.arg(after_id)"))
+				// Keyset queries are opt-in per query
+				Expect(string(content)).NotTo(ContainSubstring("name: ListPostsAfter :many"))
+			}
+		})
+
+		It("adds upsert queries when included", func() {
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin: "gen-queries",
+					Options: sqlc.CodegenOptions{
+						Queries: sqlc.QueryOptions{Include: []string{"UpsertUserByEmail", "ExecUpsertUser"}},
+					},
+				},
+			}
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			for _, config := range generator.Config.SQL {
+				content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: UpsertUserByEmail :one"))
+				Expect(string(content)).To(ContainSubstring("ON CONFLICT (email) DO UPDATE SET\n    name = EXCLUDED.name\nRETURNING *;"))
+				Expect(string(content)).To(ContainSubstring("name: ExecUpsertUser :exec"))
+				Expect(string(content)).To(ContainSubstring("ON CONFLICT (id) DO UPDATE SET\n    email = EXCLUDED.email,\n    name = EXCLUDED.name;"))
+				// Upserts are opt-in per query
+				Expect(string(content)).NotTo(ContainSubstring("name: UpsertUser :one"))
+			}
+		})
+
+		Context("when soft delete is configured", func() {
+			BeforeEach(func() {
+				table := generator.Catalog.GetTable("users")
+				table.Columns = append(table.Columns, sqlc.Column{Name: "deleted_at", Type: "timestamptz", Null: true})
+
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							SoftDelete: sqlc.SoftDeleteOptions{Column: "deleted_at"},
+						},
+					},
+				}
+			})
+
+			It("soft-deletes rows of tables with the column", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(ContainSubstring("name: DeleteUser :one\nUPDATE users\nSET\n    deleted_at = now()\nWHERE\n    id = sqlc.arg(id) AND deleted_at IS NULL\nRETURNING *;"))
+					Expect(string(content)).To(ContainSubstring("name: GetUser :one\nSELECT\n    *\nFROM\n    users\nWHERE\n    id = sqlc.arg(id) AND deleted_at IS NULL;"))
+					Expect(string(content)).To(ContainSubstring("/* query.where AND */ TRUE AND deleted_at IS NULL;"))
+					Expect(string(content)).To(ContainSubstring("name: RestoreUser :one"))
+					Expect(string(content)).To(ContainSubstring("name: HardDeleteUser :one\nDELETE FROM users"))
+				}
+			})
+
+			It("leaves tables without the column alone", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "posts.sql"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(ContainSubstring("name: DeletePost :one\nDELETE FROM posts"))
+					Expect(string(content)).NotTo(ContainSubstring("deleted_at"))
+					Expect(string(content)).NotTo(ContainSubstring("name: RestorePost :one"))
+				}
+			})
+		})
+
+		Context("when timestamp columns are configured", func() {
+			BeforeEach(func() {
+				table := generator.Catalog.GetTable("users")
+				table.Columns = append(table.Columns,
+					sqlc.Column{Name: "created_at", Type: "timestamptz"},
+					sqlc.Column{Name: "updated_at", Type: "timestamptz"},
+				)
+
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Timestamps: sqlc.TimestampOptions{
+								Created: []string{"created_at"},
+								Updated: []string{"updated_at"},
+							},
+						},
+					},
+				}
+			})
+
+			It("sets the timestamps on insert", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(ContainSubstring("    sqlc.narg(name),\n    now(),\n    now()\n)"))
+					Expect(string(content)).NotTo(ContainSubstring("sqlc.arg(created_at)"))
+					Expect(string(content)).NotTo(ContainSubstring("sqlc.arg(updated_at)"))
+				}
+			})
+
+			It("touches updated_at and leaves created_at alone on update", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).To(ContainSubstring("        ELSE name\n    END,\n    updated_at = now()\nWHERE"))
+					Expect(string(content)).NotTo(ContainSubstring("created_at = CASE"))
+				}
+			})
+		})
+
+		It("lets the database fill identity, generated and default columns on insert", func() {
+			table := generator.Catalog.GetTable("users")
+			table.Columns[0].Identity = true
+			table.Columns[2].Default = "'anonymous'"
+			table.Columns = append(table.Columns, sqlc.Column{Name: "slug", Type: "text", Generated: "lower(name)"})
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			for _, config := range generator.Config.SQL {
+				content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("INSERT INTO users (\n    email,\n    name\n) VALUES (\n    sqlc.arg(email),\n    COALESCE(sqlc.narg(name), 'anonymous')\n)"))
+				Expect(string(content)).NotTo(ContainSubstring("slug = CASE"))
+			}
+		})
+
+		It("binds identity columns on upsert only when they are in the key", func() {
+			table := generator.Catalog.GetTable("users")
+			table.Columns[0].Identity = true
+			generator.Config.SQL = generator.Config.SQL[:1]
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin:  "gen-queries",
+					Options: sqlc.CodegenOptions{Queries: sqlc.QueryOptions{Include: []string{"UpsertUser", "UpsertUserByEmail"}}},
+				},
+			}
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "users.sql"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("-- name: UpsertUser :one\nINSERT INTO users (\n    id,\n    email,\n    name\n)"))
+			Expect(string(content)).To(ContainSubstring("-- name: UpsertUserByEmail :one\nINSERT INTO users (\n    email,\n    name\n)"))
+		})
+
+		It("writes to the codegen out directory when set", func() {
+			out := filepath.Join(generator.Config.SQL[0].Queries, "generated")
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{{Plugin: "gen-queries", Out: out}}
+
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+			Expect(filepath.Join(out, "users.sql")).To(BeAnExistingFile())
+			Expect(filepath.Join(generator.Config.SQL[0].Queries, "users.sql")).NotTo(BeAnExistingFile())
+		})
+
+		It("marks the files as generated", func() {
+			Expect(generator.Generate()).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "users.sql"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HavePrefix(sqlc.Header + "-- sqlfluff:dialect:postgresql\n"))
+		})
+
+		Context("when a table is no longer generated", func() {
+			var dir string
+
+			BeforeEach(func() {
+				dir = generator.Config.SQL[0].Queries
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+				Expect(os.WriteFile(filepath.Join(dir, "custom.sql"), []byte("-- name: Custom :one\n"), 0o666)).To(Succeed())
+
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Tables: sqlc.TableOptions{Exclude: []string{"public.posts"}},
+						},
+					},
+				}
+			})
+
+			It("prunes its stale file and keeps hand-written files", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(dir, "users.sql")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, "posts.sql")).NotTo(BeAnExistingFile())
+				Expect(filepath.Join(dir, "custom.sql")).To(BeAnExistingFile())
+			})
+
+			It("keeps its stale file when pruning is disabled", func() {
+				generator.NoPrune = true
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(dir, "posts.sql")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when the SQL blocks of several generators share a directory", func() {
+			block := func(table string) *sqlc.Generator {
+				return &sqlc.Generator{
+					Catalog: generator.Catalog,
+					Config: &sqlc.Config{
+						Version: "2",
+						SQL: []sqlc.SQL{
+							{
+								Engine:  "postgresql",
+								Queries: generator.Config.SQL[0].Queries,
+								Codegen: []sqlc.Codegen{
+									{
+										Plugin:  "gen-queries",
+										Options: sqlc.CodegenOptions{Tables: sqlc.TableOptions{Include: []string{table}}},
+									},
+								},
+							},
+						},
+					},
+				}
+			}
+
+			It("keeps the files of the other blocks", func() {
+				dir := generator.Config.SQL[0].Queries
+				Expect(sqlc.Generators{block("users"), block("posts")}.Generate()).To(Succeed())
+				Expect(filepath.Join(dir, "users.sql")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, "posts.sql")).To(BeAnExistingFile())
+
+				Expect(sqlc.Generators{block("users")}.Generate()).To(Succeed())
+				Expect(filepath.Join(dir, "users.sql")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, "posts.sql")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("with a layout", func() {
+			layout := func(layout, filename string) {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin:  "gen-queries",
+						Options: sqlc.CodegenOptions{Layout: layout, Filename: filename},
+					},
+				}
+			}
+
+			It("writes one directory per schema", func() {
+				layout("per-schema-dir", "")
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				Expect(filepath.Join(dir, "public", "users.sql")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, "public", "posts.sql")).To(BeAnExistingFile())
+			})
+
+			It("writes every table to a single file", func() {
+				layout("single-file", "")
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "queries.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+				Expect(string(content)).To(ContainSubstring("name: GetPost :one"))
+				// The file header is written once
+				Expect(strings.Count(string(content), "-- sqlfluff:dialect:postgresql")).To(Equal(1))
+				Expect(string(content)).NotTo(ContainSubstring("\n\n\n"))
+			})
+
+			It("splits reads and writes", func() {
+				layout("per-query-kind", "")
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "reads.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+				Expect(string(content)).To(ContainSubstring("name: ListPosts :many"))
+				Expect(string(content)).NotTo(ContainSubstring("name: InsertUser :one"))
+
+				content, err = os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "writes.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: InsertUser :one"))
+				Expect(string(content)).NotTo(ContainSubstring("name: GetUser :one"))
+			})
+
+			It("names the files from the pattern", func() {
+				layout("", "{{.Schema}}_{{.Table}}.gen.sql")
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				Expect(filepath.Join(generator.Config.SQL[0].Queries, "public_users.gen.sql")).To(BeAnExistingFile())
+			})
+
+			When("the layout is unknown", func() {
+				It("returns an error", func() {
+					layout("per-column", "")
+					Expect(generator.Generate()).To(MatchError(ContainSubstring("unsupported layout")))
+				})
+			})
+
+			When("the pattern refers to an unknown field", func() {
+				It("returns an error", func() {
+					layout("", "{{.Title}}.sql")
+					Expect(generator.Generate()).To(MatchError(ContainSubstring("rendering the file name")))
+				})
+			})
+		})
+
+		Context("with custom templates", func() {
+			var dir string
+
+			BeforeEach(func() {
+				dir = GinkgoT().TempDir()
+				Expect(os.WriteFile(filepath.Join(dir, "list.tmpl"), []byte(`{{define "List"}}
+-- name: List{{table_name .Name "many"}}Custom :many
+SELECT * FROM {{.Ident}};
+{{- end}}`), 0o600)).To(Succeed())
+			})
+
+			It("overrides the blocks with the templates option", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{Plugin: "gen-queries", Options: sqlc.CodegenOptions{Templates: dir}},
+				}
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("-- name: ListUsersCustom :many\nSELECT * FROM users;"))
+				Expect(string(content)).NotTo(ContainSubstring("name: ListUsers :many"))
+				// The other blocks are kept
+				Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+			})
+
+			It("leaves the custom queries out of the presets", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "list.tmpl"), []byte(`{{define "List"}}
+{{- if should_generate . "ListUsersByAge" ":many" true}}
+-- name: ListUsersByAge :many
+SELECT * FROM {{.Ident}} ORDER BY age;
+{{- end}}
+{{- end}}`), 0o600)).To(Succeed())
+
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Templates: dir,
+							Queries:   sqlc.QueryOptions{Exclude: []string{"writes", "all"}},
+						},
+					},
+				}
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("-- name: ListUsersByAge :many"))
+				Expect(string(content)).NotTo(ContainSubstring("name: InsertUser :one"))
+			})
+
+			It("falls back to the template directory of the generator", func() {
+				generator.TemplateDir = dir
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: ListUsersCustom :many"))
+			})
+		})
+
+		Context("with reserved and mixed-case names", func() {
+			BeforeEach(func() {
+				key := &sqlc.Index{Name: "primary key", Parts: []sqlc.IndexPart{{Column: "id"}}}
+
+				generator.Catalog = &sqlc.Catalog{
+					Schemas: []sqlc.Schema{
+						{
+							Name: "public",
+							Tables: []sqlc.Table{
+								{
+									Name: "order",
+									Columns: []sqlc.Column{
+										{Name: "id", Type: "integer"},
+										{Name: "user", Type: "text"},
+										{Name: "createdAt", Type: "timestamp"},
+									},
+									PrimaryKey: key,
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("quotes the identifiers and sanitizes the argument names", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "order.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("FROM\n    \"order\"\nWHERE\n    id = sqlc.arg(id)"))
+				Expect(string(content)).To(ContainSubstring("INSERT INTO \"order\" (\n    id,\n    \"user\",\n    \"createdAt\"\n)"))
+				Expect(string(content)).To(ContainSubstring(`sqlc.arg("user")`))
+				Expect(string(content)).To(ContainSubstring("sqlc.arg(created_at)"))
+				Expect(string(content)).To(ContainSubstring("\"createdAt\" = CASE"))
+			})
+		})
+
+		Context("with a foreign key into another schema", func() {
+			BeforeEach(func() {
+				key := &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}}
+				columns := []sqlc.Column{{Name: "id", Type: "integer"}, {Name: "account_id", Type: "integer"}}
+
+				// Atlas catalogs never qualify the referenced table
+				fk := sqlc.ForeignKey{Name: "posts_account_id_fkey", Columns: []string{"account_id"}}
+				fk.References.Table = "accounts"
+				fk.References.Columns = []string{"id"}
+
+				generator.Config.SQL = generator.Config.SQL[:1]
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin:  "gen-queries",
+						Options: sqlc.CodegenOptions{Queries: sqlc.QueryOptions{Include: []string{"GetPostWithAccount"}}},
+					},
+				}
+				generator.Catalog = &sqlc.Catalog{
+					Schemas: []sqlc.Schema{
+						{
+							Name:   "public",
+							Tables: []sqlc.Table{{Name: "posts", Columns: columns, PrimaryKey: key, ForeignKeys: []sqlc.ForeignKey{fk}}},
+						},
+						{
+							Name:   "billing",
+							Tables: []sqlc.Table{{Name: "accounts", Columns: columns[:1], PrimaryKey: key}},
+						},
+					},
+				}
+			})
+
+			It("joins the only table with the referenced name", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("INNER JOIN billing.accounts ON posts.account_id = billing.accounts.id"))
+			})
+
+			When("the referenced table does not exist", func() {
+				It("returns an error", func() {
+					generator.Catalog.Schemas = generator.Catalog.Schemas[:1]
+					Expect(generator.Generate()).To(MatchError(ContainSubstring(`foreign key posts_account_id_fkey references table "accounts", which does not exist`)))
+				})
+			})
+		})
+
+		Context("with same-named tables in several schemas", func() {
+			BeforeEach(func() {
+				key := &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}}
+				columns := []sqlc.Column{{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}}
+
+				sessionsUser := sqlc.ForeignKey{Name: "sessions_user_id_fkey", Columns: []string{"user_id"}}
+				sessionsUser.References.Table = "users"
+				sessionsUser.References.Columns = []string{"id"}
+
+				postsUser := sqlc.ForeignKey{Name: "posts_user_id_fkey", Columns: []string{"user_id"}}
+				postsUser.References.Schema = "auth"
+				postsUser.References.Table = "users"
+				postsUser.References.Columns = []string{"id"}
+
+				generator.Catalog = &sqlc.Catalog{
+					Schemas: []sqlc.Schema{
+						{
+							Name: "public",
+							Tables: []sqlc.Table{
+								{Name: "users", Columns: columns[:1], PrimaryKey: key},
+								{Name: "posts", Columns: columns, PrimaryKey: key, ForeignKeys: []sqlc.ForeignKey{postsUser}},
+							},
+						},
+						{
+							Name: "auth",
+							Tables: []sqlc.Table{
+								{Name: "users", Columns: columns[:1], PrimaryKey: key},
+								{Name: "sessions", Columns: columns, PrimaryKey: key, ForeignKeys: []sqlc.ForeignKey{sessionsUser}},
+							},
+						},
+					},
+				}
+			})
+
+			It("prefixes the clashing table outside the default schema", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Queries: sqlc.QueryOptions{Include: []string{"GetPostWithUser", "GetSessionWithUser"}},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				content, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+
+				content, err = os.ReadFile(filepath.Join(dir, "auth_users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SET search_path TO auth;"))
+				Expect(string(content)).To(ContainSubstring("name: GetAuthUser :one"))
+
+				// Tables without a clash keep their names
+				content, err = os.ReadFile(filepath.Join(dir, "sessions.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("INNER JOIN users ON sessions.user_id = users.id"))
+
+				content, err = os.ReadFile(filepath.Join(dir, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("INNER JOIN auth.users ON posts.user_id = auth.users.id"))
+			})
+
+			It("emits quoted, schema-qualified identifiers when qualify is set", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Qualify: true,
+							Queries: sqlc.QueryOptions{Include: []string{"GetPostWithUser"}},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				content, err := os.ReadFile(filepath.Join(dir, "auth_users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).NotTo(ContainSubstring("SET search_path"))
+				Expect(string(content)).To(ContainSubstring("FROM\n    \"auth\".\"users\""))
+				Expect(string(content)).To(ContainSubstring("DELETE FROM \"auth\".\"users\""))
+
+				content, err = os.ReadFile(filepath.Join(dir, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("INSERT INTO \"public\".\"posts\""))
+				Expect(string(content)).To(ContainSubstring(`INNER JOIN "auth"."users" ON "public"."posts"."user_id" = "auth"."users"."id"`))
+			})
+
+			When("the tables are never prefixed", func() {
+				It("returns an error", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{Plugin: "gen-queries", Options: sqlc.CodegenOptions{SchemaPrefix: "never"}},
+					}
+					Expect(generator.Generate()).To(MatchError(ContainSubstring("tables public.users and auth.users both generate queries")))
+				})
+			})
+
+			When("the prefixed name clashes as well", func() {
+				It("returns an error", func() {
+					generator.Catalog.Schemas[0].Tables = append(generator.Catalog.Schemas[0].Tables, sqlc.Table{Name: "auth_users"})
+					Expect(generator.Generate()).To(MatchError(ContainSubstring(`named after "AuthUser"`)))
+				})
+			})
+
+			When("the file name pattern ignores the schema", func() {
+				It("returns an error", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{Plugin: "gen-queries", Options: sqlc.CodegenOptions{Filename: "{{.Table}}.sql"}},
+					}
+					Expect(generator.Generate()).To(MatchError(ContainSubstring("are both written to users.sql")))
+				})
+			})
+		})
+
 		It("excludes default queries listed in exclude", func() {
 			dir := generator.Config.SQL[0].Queries
 			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
@@ -231,6 +853,422 @@ var _ = Describe("Generator", func() {
 			}
 		})
 
+		Context("with patterns in the include and exclude lists", func() {
+			var buffer bytes.Buffer
+
+			BeforeEach(func() {
+				buffer.Reset()
+				logger := slog.Default()
+				slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+				DeferCleanup(slog.SetDefault, logger)
+			})
+
+			It("matches table and query names with globs and regular expressions", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Tables: sqlc.TableOptions{Exclude: []string{"public.p*"}},
+							Queries: sqlc.QueryOptions{
+								Include: []string{"*ByEmail"},
+								Exclude: []string{"Batch*", `re:^(Exec)?Delete`},
+							},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				Expect(filepath.Join(dir, "posts.sql")).NotTo(BeAnExistingFile())
+
+				content, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetUserByEmail :one"))
+				Expect(string(content)).To(ContainSubstring("name: UpdateUserByEmail :one"))
+				Expect(string(content)).NotTo(ContainSubstring("name: BatchGetUsersByEmail"))
+				Expect(string(content)).NotTo(ContainSubstring("name: DeleteUser"))
+				Expect(string(content)).NotTo(ContainSubstring("name: ExecDeleteUser"))
+				Expect(string(content)).To(ContainSubstring("name: ListUsers :many"))
+
+				Expect(buffer.String()).NotTo(ContainSubstring("The pattern matches nothing"))
+			})
+
+			It("reports the entries that match nothing", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Tables:  sqlc.TableOptions{Exclude: []string{"audit_*"}},
+							Queries: sqlc.QueryOptions{Include: []string{"GetUserByPhone"}},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("option=tables.exclude pattern=audit_*"))
+				Expect(buffer.String()).To(ContainSubstring("option=queries.include pattern=GetUserByPhone"))
+			})
+
+			It("suggests the closest name", func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Tables:  sqlc.TableOptions{Exclude: []string{"public.post"}},
+							Queries: sqlc.QueryOptions{Include: []string{"CopyUser"}},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("option=tables.exclude pattern=public.post engine=postgresql did_you_mean=public.posts"))
+				Expect(buffer.String()).To(ContainSubstring("option=queries.include pattern=CopyUser engine=postgresql did_you_mean=CopyUsers"))
+			})
+
+			Context("when strict", func() {
+				BeforeEach(func() {
+					generator.Strict = true
+				})
+
+				It("returns an error for every entry that matches nothing", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{
+							Plugin: "gen-queries",
+							Options: sqlc.CodegenOptions{
+								Tables:  sqlc.TableOptions{Exclude: []string{"audit_*"}},
+								Queries: sqlc.QueryOptions{Include: []string{"CopyUser"}},
+							},
+						},
+					}
+
+					err := generator.Generate()
+					Expect(err).To(MatchError(ContainSubstring(`queries.include: "CopyUser" matches nothing, did you mean "CopyUsers"?`)))
+					Expect(err).To(MatchError(ContainSubstring(`tables.exclude: "audit_*" matches nothing`)))
+					Expect(filepath.Join(generator.Config.SQL[0].Queries, "users.sql")).NotTo(BeAnExistingFile())
+				})
+
+				It("returns an error for the override entries that match no query of their tables", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{
+							Plugin: "gen-queries",
+							Options: sqlc.CodegenOptions{
+								Overrides: map[string]sqlc.TableOverride{
+									"users": {Queries: sqlc.QueryOptions{Include: []string{"CopyUser", "CopyUsers"}}},
+									"posts": {Queries: sqlc.QueryOptions{Exclude: []string{"GetUser", "GetPost"}}},
+								},
+							},
+						},
+					}
+
+					err := generator.Generate()
+					Expect(err).To(MatchError(ContainSubstring(`overrides.users.queries.include: "CopyUser" matches nothing, did you mean "CopyUsers"?`)))
+					Expect(err).To(MatchError(ContainSubstring(`overrides.posts.queries.exclude: "GetUser" matches nothing`)))
+					Expect(err).NotTo(MatchError(ContainSubstring(`"CopyUsers" matches nothing`)))
+					Expect(err).NotTo(MatchError(ContainSubstring(`"GetPost" matches nothing`)))
+				})
+
+				It("accepts entries that match", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{
+							Plugin: "gen-queries",
+							Options: sqlc.CodegenOptions{
+								Queries: sqlc.QueryOptions{Include: []string{"CopyUsers"}},
+							},
+						},
+					}
+
+					Expect(generator.Generate()).To(Succeed())
+				})
+			})
+
+			Context("when a pattern is invalid", func() {
+				It("returns an error", func() {
+					generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+						{
+							Plugin: "gen-queries",
+							Options: sqlc.CodegenOptions{
+								Queries: sqlc.QueryOptions{Exclude: []string{"re:("}},
+							},
+						},
+					}
+
+					Expect(generator.Generate()).To(MatchError(ContainSubstring(`queries.exclude: invalid pattern "re:("`)))
+				})
+			})
+		})
+
+		Context("with per-table overrides", func() {
+			BeforeEach(func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Queries: sqlc.QueryOptions{Exclude: []string{"Batch*"}},
+							Overrides: map[string]sqlc.TableOverride{
+								"users": {
+									Queries: sqlc.QueryOptions{Include: []string{"CopyUsers"}},
+									Columns: sqlc.ColumnOptions{Exclude: []string{"name"}},
+								},
+								"public.p*": {
+									Queries: sqlc.QueryOptions{Exclude: []string{"*Insert*", "*Update*", "*Delete*"}},
+									Name:    "articles",
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("applies the options of each table over the block defaults", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				content, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: CopyUsers :copyfrom"))
+				Expect(string(content)).To(ContainSubstring("name: InsertUser :one"))
+				Expect(string(content)).NotTo(ContainSubstring("name: BatchGetUsers"))
+				Expect(string(content)).To(ContainSubstring("RETURNING id, email;"))
+
+				Expect(filepath.Join(dir, "posts.sql")).NotTo(BeAnExistingFile())
+				content, err = os.ReadFile(filepath.Join(dir, "articles.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetArticle :one"))
+				Expect(string(content)).To(ContainSubstring("name: ListArticlesByUserId :many"))
+				Expect(string(content)).NotTo(ContainSubstring("name: InsertArticle"))
+				Expect(string(content)).NotTo(ContainSubstring("name: UpdateArticle"))
+				Expect(string(content)).NotTo(ContainSubstring("name: BatchGetArticles"))
+			})
+
+			Context("when an override key is an invalid pattern", func() {
+				It("returns an error", func() {
+					generator.Config.SQL[0].Codegen[0].Options.Overrides["audit_["] = sqlc.TableOverride{}
+					Expect(generator.Generate()).To(MatchError(ContainSubstring(`overrides: invalid pattern "audit_["`)))
+				})
+			})
+		})
+
+		Context("with excluded and read-only columns", func() {
+			BeforeEach(func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Columns: sqlc.ColumnOptions{Readonly: []string{"title"}},
+							Overrides: map[string]sqlc.TableOverride{
+								"users": {Columns: sqlc.ColumnOptions{Exclude: []string{"name"}, Readonly: []string{"id"}}},
+							},
+						},
+					},
+				}
+			})
+
+			It("selects and returns the remaining columns by name", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				content, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SELECT\n    id, email\nFROM"))
+				Expect(string(content)).To(ContainSubstring("RETURNING id, email;"))
+				Expect(string(content)).NotTo(ContainSubstring("*\n"))
+				// Excluded columns are still written
+				Expect(string(content)).To(ContainSubstring("INSERT INTO users (\n    email,\n    name\n)"))
+
+				content, err = os.ReadFile(filepath.Join(dir, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SELECT\n    *\nFROM"))
+				Expect(string(content)).To(ContainSubstring("RETURNING *;"))
+			})
+
+			It("never writes the read-only columns", func() {
+				tables, err := generator.Queries(&generator.Config.SQL[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tables[1].Table.Name).To(Equal("posts"))
+
+				// Upserts bind their conflict target all the same
+				for _, query := range append(tables[0].Queries, tables[0].Skipped...) {
+					switch query.Name {
+					case "InsertUser":
+						Expect(query.Params).NotTo(ContainElement("id"))
+					case "UpsertUser":
+						Expect(query.Params).To(ContainElement("id"))
+					case "UpsertUserByEmail":
+						Expect(query.Params).NotTo(ContainElement("id"))
+					}
+				}
+
+				for _, query := range append(tables[1].Queries, tables[1].Skipped...) {
+					switch query.Name {
+					case "InsertPost", "CopyPosts", "UpdatePost", "UpsertPost", "UpdatePostsByUserId":
+						Expect(query.Params).NotTo(ContainElement("title"), query.Name)
+					case "UpdatePostsByTitle":
+						Expect(query.Params).To(ContainElement("title"))
+					}
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).NotTo(ContainSubstring("title = CASE"))
+				Expect(string(content)).NotTo(ContainSubstring("sqlc.arg(title)"))
+			})
+
+			It("resolves the options of the joined tables with the queries", func() {
+				tables, err := generator.Queries(&generator.Config.SQL[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tables[1].Table.Name).To(Equal("posts"))
+				Expect(tables[1].Joins).To(HaveKey("public.users"))
+				Expect(tables[1].Joins["public.users"].ExcludeColumns).To(HaveKey("name"))
+			})
+
+			It("selects the joined tables with excluded columns by name", func() {
+				options := &generator.Config.SQL[0].Codegen[0].Options
+				options.Queries.Include = []string{"GetPostWithUser", "BatchGetPostsWithUser"}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("-- name: GetPostWithUser :one\nSELECT\n    sqlc.embed(posts), users.id, users.email\nFROM"))
+				Expect(string(content)).To(ContainSubstring("-- name: BatchGetPostsWithUser :batchone\nSELECT\n    sqlc.embed(posts), users.id, users.email\nFROM"))
+
+				options.Overrides["posts"] = sqlc.TableOverride{Columns: sqlc.ColumnOptions{Exclude: []string{"content"}}}
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				content, err = os.ReadFile(filepath.Join(generator.Config.SQL[0].Queries, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("SELECT\n    posts.id, posts.user_id, posts.title, posts.author_id, users.id, users.email\nFROM"))
+				Expect(string(content)).NotTo(ContainSubstring("sqlc.embed"))
+			})
+		})
+
+		Context("with query presets", func() {
+			BeforeEach(func() {
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Queries: sqlc.QueryOptions{Include: []string{"joins"}, Exclude: []string{"batch"}},
+							Overrides: map[string]sqlc.TableOverride{
+								"users": {Queries: sqlc.QueryOptions{Exclude: []string{"writes"}}},
+							},
+						},
+					},
+				}
+			})
+
+			It("selects the queries of each preset", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				dir := generator.Config.SQL[0].Queries
+				content, err := os.ReadFile(filepath.Join(dir, "users.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+				Expect(string(content)).NotTo(ContainSubstring("name: InsertUser"))
+				Expect(string(content)).NotTo(ContainSubstring("name: BatchGetUsers"))
+
+				content, err = os.ReadFile(filepath.Join(dir, "posts.sql"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("name: GetPostWithUser :one"))
+				Expect(string(content)).To(ContainSubstring("name: InsertPost :one"))
+				Expect(string(content)).NotTo(ContainSubstring("name: BatchGetPostsWithUser"))
+			})
+		})
+
+		Context("with the mysql engine", func() {
+			BeforeEach(func() {
+				generator.Config.SQL[0].Engine = "mysql"
+			})
+
+			It("generates queries without PostgreSQL-only syntax", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(string(content)).NotTo(ContainSubstring("SET search_path"))
+					Expect(string(content)).NotTo(ContainSubstring("RETURNING"))
+					Expect(string(content)).NotTo(ContainSubstring("::int"))
+					Expect(string(content)).NotTo(ContainSubstring("any(sqlc.arg(update_mask))"))
+
+					Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
+					Expect(string(content)).To(ContainSubstring("name: InsertUser :execlastid"))
+					Expect(string(content)).To(ContainSubstring("name: UpdateUser :execrows"))
+					Expect(string(content)).To(ContainSubstring("name: DeleteUser :execrows"))
+					Expect(string(content)).To(ContainSubstring("FIND_IN_SET('email', sqlc.arg(update_mask)) > 0"))
+					Expect(string(content)).To(ContainSubstring("sqlc.arg(skip), sqlc.arg(take);"))
+
+					// Batch queries require pgx and are skipped
+					Expect(string(content)).NotTo(ContainSubstring("name: BatchGetUsers"))
+				}
+			})
+		})
+
+		Context("with the sqlite engine", func() {
+			BeforeEach(func() {
+				generator.Config.SQL[0].Engine = "sqlite"
+			})
+
+			It("generates queries with SQLite-compatible placeholders", func() {
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(string(content)).NotTo(ContainSubstring("SET search_path"))
+					Expect(string(content)).NotTo(ContainSubstring("::int"))
+					Expect(string(content)).NotTo(ContainSubstring("sqlc.arg(update_mask)"))
+
+					Expect(string(content)).To(ContainSubstring("name: InsertUser :one"))
+					Expect(string(content)).To(ContainSubstring("RETURNING *;"))
+					Expect(string(content)).To(ContainSubstring("WHEN CAST(sqlc.narg(update_email) AS BOOLEAN)"))
+					Expect(string(content)).To(ContainSubstring("LIMIT\n    sqlc.arg(take)\nOFFSET\n    sqlc.arg(skip);"))
+
+					// Batch queries require pgx and are skipped
+					Expect(string(content)).NotTo(ContainSubstring("name: BatchGetUsers"))
+				}
+			})
+
+			It("skips included queries the engine does not support with a warning", func() {
+				var buffer bytes.Buffer
+				logger := slog.Default()
+				slog.SetDefault(slog.New(slog.NewTextHandler(&buffer, nil)))
+				DeferCleanup(slog.SetDefault, logger)
+
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin: "gen-queries",
+						Options: sqlc.CodegenOptions{
+							Queries: sqlc.QueryOptions{Include: []string{"CopyUsers"}},
+						},
+					},
+				}
+
+				Expect(generator.Generate()).NotTo(HaveOccurred())
+
+				for _, config := range generator.Config.SQL {
+					content, err := os.ReadFile(filepath.Join(config.Queries, "users.sql"))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(content)).NotTo(ContainSubstring("name: CopyUsers"))
+				}
+
+				Expect(buffer.String()).To(ContainSubstring("level=WARN"))
+				Expect(buffer.String()).To(ContainSubstring("query=CopyUsers"))
+			})
+		})
+
+		When("the engine is not supported", func() {
+			It("returns an error", func() {
+				generator.Config.SQL[0].Engine = "oracle"
+				Expect(generator.Generate()).To(MatchError(ContainSubstring("unsupported engine")))
+			})
+		})
+
 		When("the queries directory does not exist", func() {
 			It("returns an error", func() {
 				for index := range generator.Config.SQL {
@@ -381,7 +1419,7 @@ var _ = Describe("Generator", func() {
 					content, err := os.ReadFile(path)
 					Expect(err).NotTo(HaveOccurred())
 
-					// Defaults are still present; unknown include names are ignored
+					// Defaults are still present; unknown include names only log a warning
 					Expect(string(content)).To(ContainSubstring("name: GetUser :one"))
 					Expect(string(content)).To(ContainSubstring("name: InsertUser :one"))
 					Expect(string(content)).To(ContainSubstring("name: ListUsers :many"))
@@ -0,0 +1,188 @@
+package sqlc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Layout describes how the generated queries are split into files.
+type Layout struct {
+	// Name is the layout name used in the `layout` option.
+	Name string
+	// Filename is the default file name pattern, relative to the output
+	// directory.
+	Filename string
+	// Sections lists the query sections that are rendered into separate
+	// files for every table. An empty section holds every query.
+	Sections []string
+}
+
+// Layouts holds the supported layouts keyed by name.
+var Layouts = map[string]*Layout{
+	"per-table": {
+		Name:     "per-table",
+		Filename: "{{.Name}}.sql",
+		Sections: []string{""},
+	},
+	"per-schema-dir": {
+		Name:     "per-schema-dir",
+		Filename: "{{.Schema}}/{{.Table}}.sql",
+		Sections: []string{""},
+	},
+	"single-file": {
+		Name:     "single-file",
+		Filename: "queries.sql",
+		Sections: []string{""},
+	},
+	"per-query-kind": {
+		Name:     "per-query-kind",
+		Filename: "{{.Section}}.sql",
+		Sections: []string{"reads", "writes"},
+	},
+}
+
+// GetLayout returns the layout with the given name. An empty name defaults
+// to per-table.
+func GetLayout(name string) (*Layout, error) {
+	if name == "" {
+		name = "per-table"
+	}
+
+	if layout, ok := Layouts[name]; ok {
+		return layout, nil
+	}
+
+	return nil, fmt.Errorf("unsupported layout %q", name)
+}
+
+// FileName is the data available to a file name pattern.
+type FileName struct {
+	// Engine is the sqlc engine name.
+	Engine string
+	// Schema is the schema of the table.
+	Schema string
+	// Table is the table name.
+	Table string
+	// Name is the table name, prefixed with the schema when it clashes with
+	// a table in another schema (see SchemaPrefix).
+	Name string
+	// Section is the query section (reads or writes), or empty when the
+	// layout does not split queries.
+	Section string
+}
+
+// ParseFileName parses a file name pattern. An empty pattern uses the
+// default pattern of the layout.
+func (x *Layout) ParseFileName(pattern string) (*template.Template, error) {
+	if pattern == "" {
+		pattern = x.Filename
+	}
+
+	return template.New("filename").Option("missingkey=error").Parse(pattern)
+}
+
+// perTable reports whether the file name pattern gives every table its own
+// file, so that two tables sharing a file is a clash rather than intended.
+func perTable(pattern *template.Template) bool {
+	var left, right strings.Builder
+	// Tables that differ only by name must land in different files
+	if err := pattern.Execute(&left, &FileName{Table: "a", Name: "a"}); err != nil {
+		return false
+	}
+	if err := pattern.Execute(&right, &FileName{Table: "b", Name: "b"}); err != nil {
+		return false
+	}
+	return left.String() != right.String()
+}
+
+// SchemaPrefixes lists the strategies for naming tables that live outside
+// the default schema of the engine:
+//
+//   - clash (default) prefixes a table with its schema only when a table with
+//     the same name exists in another schema, e.g. auth.users becomes
+//     auth_users (AuthUser) next to public.users (User).
+//   - always prefixes every table outside the default schema.
+//   - never keeps the table names as they are.
+var SchemaPrefixes = []string{"clash", "always", "never"}
+
+// tableNames returns the names the selected tables are generated under,
+// keyed by schema-qualified table name. Names set by the overrides are kept
+// as they are, and the singular and plural forms they set are recorded in the
+// naming under the generated name. It fails when two tables would still
+// produce the same query names.
+func tableNames(catalog *Catalog, schema string, config *SQL, naming *Naming) (map[string]string, error) {
+	strategy := cmp.Or(config.GetOptions().SchemaPrefix, "clash")
+	if !slices.Contains(SchemaPrefixes, strategy) {
+		return nil, fmt.Errorf("unsupported schema prefix %q", strategy)
+	}
+
+	include := config.GetIncludeSet()
+	exclude := config.GetExcludeSet()
+
+	// Count the schemas each table name appears in
+	counts := make(map[string]int)
+	for _, item := range catalog.Schemas {
+		for _, table := range item.Tables {
+			if tableSelected(include, exclude, item.Name, table.Name) && config.GetTableConfig(item.Name, table.Name).Name == "" {
+				counts[table.Name]++
+			}
+		}
+	}
+
+	names := make(map[string]string)
+	owners := make(map[string]string)
+
+	for _, item := range catalog.Schemas {
+		for _, table := range item.Tables {
+			if !tableSelected(include, exclude, item.Name, table.Name) {
+				continue
+			}
+
+			tableConfig := config.GetTableConfig(item.Name, table.Name)
+
+			var prefix bool
+			switch strategy {
+			case "clash":
+				prefix = item.Name != schema && counts[table.Name] > 1
+			case "always":
+				prefix = item.Name != schema
+			}
+
+			name := table.Name
+			if prefix {
+				name = item.Name + "_" + table.Name
+			}
+			name = cmp.Or(tableConfig.Name, name)
+			naming.set(name, tableConfig.Singular, tableConfig.Plural)
+
+			qualified := item.Name + "." + table.Name
+			// Query names are derived from the singular form
+			key := naming.tableName(name, false)
+			if owner, ok := owners[key]; ok {
+				return nil, fmt.Errorf("tables %s and %s both generate queries named after %q; "+
+					"change the schema_prefix option or exclude one of them", owner, qualified, key)
+			}
+
+			owners[key] = qualified
+			names[qualified] = name
+		}
+	}
+
+	return names, nil
+}
+
+// readPrefixes are the name prefixes of queries that only read rows.
+var readPrefixes = []string{"Get", "BatchGet", "List", "Count", "Exists"}
+
+// querySection returns the section (reads or writes) of the named query.
+func querySection(name string) string {
+	for _, prefix := range readPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return "reads"
+		}
+	}
+	return "writes"
+}
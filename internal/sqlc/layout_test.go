@@ -0,0 +1,52 @@
+package sqlc_test
+
+import (
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Layout", func() {
+	Describe("GetLayout", func() {
+		It("returns the layout for a known name", func() {
+			layout, err := sqlc.GetLayout("per-schema-dir")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layout.Filename).To(Equal("{{.Schema}}/{{.Table}}.sql"))
+		})
+
+		It("defaults to per-table when the name is empty", func() {
+			layout, err := sqlc.GetLayout("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(layout.Name).To(Equal("per-table"))
+		})
+
+		When("the layout is unknown", func() {
+			It("returns an error", func() {
+				_, err := sqlc.GetLayout("per-column")
+				Expect(err).To(MatchError(ContainSubstring(`unsupported layout "per-column"`)))
+			})
+		})
+	})
+
+	Describe("ParseFileName", func() {
+		It("uses the layout pattern when the pattern is empty", func() {
+			layout, err := sqlc.GetLayout("per-table")
+			Expect(err).NotTo(HaveOccurred())
+
+			pattern, err := layout.ParseFileName("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pattern.Root.String()).To(Equal("{{.Name}}.sql"))
+		})
+
+		When("the pattern is malformed", func() {
+			It("returns an error", func() {
+				layout, err := sqlc.GetLayout("per-table")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = layout.ParseFileName("{{.Table")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package sqlc
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/go-openapi/inflect"
+)
+
+// Naming inflects the table names into the singular and plural names the
+// queries are named after, e.g. GetUser and ListUsers. It applies the rules
+// of the naming option on top of the built-in English rules, and the names
+// set per table by the overrides (see NamingOptions).
+type Naming struct {
+	rules *inflect.Ruleset
+	// tables holds the explicit singular and plural names by table name
+	tables map[string][2]string
+}
+
+// newNaming returns the naming with the given rules. Rules are suffix rules
+// that apply to the end of the names, so that a rule for data applies to
+// user_data as well.
+func newNaming(options NamingOptions) *Naming {
+	rules := inflect.NewDefaultRuleset()
+	rules.AddSingular("quota", "quota")
+	rules.AddPlural("quota", "quotas")
+
+	// Rules added last take precedence; sort them for stable results
+	for _, suffix := range slices.Sorted(maps.Keys(options.Singular)) {
+		rules.AddSingular(suffix, options.Singular[suffix])
+	}
+	for _, suffix := range slices.Sorted(maps.Keys(options.Plural)) {
+		rules.AddPlural(suffix, options.Plural[suffix])
+	}
+	for _, singular := range slices.Sorted(maps.Keys(options.Irregular)) {
+		rules.AddIrregular(singular, options.Irregular[singular])
+	}
+	for _, word := range options.Uncountable {
+		rules.AddSingular(word, word)
+		rules.AddPlural(word, word)
+	}
+
+	return &Naming{rules: rules, tables: make(map[string][2]string)}
+}
+
+// set records the explicit singular and plural names of the table. A missing
+// form is inflected from the other one.
+func (x *Naming) set(table, singular, plural string) {
+	if singular == "" && plural == "" {
+		return
+	}
+	x.tables[table] = [2]string{
+		cmp.Or(singular, x.rules.Singularize(plural)),
+		cmp.Or(plural, x.rules.Pluralize(singular)),
+	}
+}
+
+// Singular returns the singular form of the word.
+func (x *Naming) Singular(word string) string {
+	if forms, ok := x.tables[word]; ok {
+		return forms[0]
+	}
+	return x.rules.Singularize(word)
+}
+
+// Plural returns the plural form of the word.
+func (x *Naming) Plural(word string) string {
+	if forms, ok := x.tables[word]; ok {
+		return forms[1]
+	}
+	return x.rules.Pluralize(word)
+}
+
+// tableName returns the singular or plural Go name of a table.
+func (x *Naming) tableName(table string, plural bool) string {
+	if plural {
+		return x.rules.Camelize(x.Plural(table))
+	}
+	return x.rules.Camelize(x.Singular(table))
+}
@@ -0,0 +1,98 @@
+package sqlc_test
+
+import (
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Naming", func() {
+	var generator *sqlc.Generator
+
+	BeforeEach(func() {
+		catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"user_data", "licoes"} {
+			catalog.Schemas[0].Tables = append(catalog.Schemas[0].Tables, sqlc.Table{
+				Name:       name,
+				Columns:    []sqlc.Column{{Name: "id"}},
+				PrimaryKey: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}},
+			})
+		}
+
+		generator = &sqlc.Generator{
+			Catalog: catalog,
+			Config: &sqlc.Config{
+				SQL: []sqlc.SQL{{Engine: "postgresql", Queries: "queries"}},
+			},
+		}
+	})
+
+	// names returns the names of the selected queries of every table
+	names := func() []string {
+		tables, err := generator.Queries(&generator.Config.SQL[0])
+		Expect(err).NotTo(HaveOccurred())
+
+		var items []string
+		for _, table := range tables {
+			for _, query := range table.Queries {
+				items = append(items, query.Name)
+			}
+		}
+		return items
+	}
+
+	// options sets the options of the gen-queries plugin
+	options := func(options sqlc.CodegenOptions) {
+		generator.Config.SQL[0].Codegen = []sqlc.Codegen{{Plugin: "gen-queries", Options: options}}
+	}
+
+	It("inflects the names with the built-in rules", func() {
+		Expect(names()).To(ContainElements("GetUserDatum", "ListUserData", "GetLico", "ListLicoes"))
+	})
+
+	It("applies the rules of the naming option", func() {
+		options(sqlc.CodegenOptions{
+			Naming: sqlc.NamingOptions{
+				Singular:    map[string]string{"oes": "ao"},
+				Plural:      map[string]string{"ao": "oes"},
+				Uncountable: []string{"data"},
+			},
+		})
+
+		Expect(names()).To(ContainElements("GetUserData", "ListUserData", "GetLicao", "ListLicoes"))
+		Expect(names()).NotTo(ContainElement("GetUserDatum"))
+	})
+
+	It("uses the names set per table", func() {
+		options(sqlc.CodegenOptions{
+			Overrides: map[string]sqlc.TableOverride{
+				"posts":       {Singular: "entry", Plural: "entries"},
+				"user_data":   {Singular: "user_data"},
+				"public.lic*": {Plural: "lessons"},
+			},
+		})
+
+		Expect(names()).To(ContainElements(
+			"GetEntry", "ListEntries", "ListEntriesByUserId",
+			"GetUserData", "ListUserData",
+			"GetLesson", "ListLessons",
+		))
+		Expect(names()).NotTo(ContainElement("GetPost"))
+	})
+
+	When("two tables are given the same singular name", func() {
+		It("returns an error", func() {
+			options(sqlc.CodegenOptions{
+				Overrides: map[string]sqlc.TableOverride{
+					"posts": {Singular: "user"},
+				},
+			})
+
+			_, err := generator.Queries(&generator.Config.SQL[0])
+			Expect(err).To(MatchError(ContainSubstring(`both generate queries named after "User"`)))
+		})
+	})
+})
@@ -0,0 +1,232 @@
+package sqlc
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RegexpPrefix marks a pattern as a regular expression.
+const RegexpPrefix = "re:"
+
+// expressions caches the compiled regular expressions by pattern.
+var expressions sync.Map
+
+// Patterns is a set of names and patterns as listed in the include and
+// exclude options. An entry is either an exact name, a glob pattern such as
+// audit_*, auth.* or Batch* (see path.Match), or a regular expression with
+// the re: prefix, e.g. re:^audit_\d+$. Regular expressions match anywhere in
+// the name unless anchored.
+type Patterns map[string]bool
+
+// newPatterns returns the set of the given entries.
+func newPatterns(entries []string) Patterns {
+	patterns := make(Patterns, len(entries))
+	for _, entry := range entries {
+		patterns[entry] = true
+	}
+	return patterns
+}
+
+// QueryPresets holds the presets that may be listed in the query include and
+// exclude options, keyed by name. They match the queries of the query model
+// by kind rather than by name.
+var QueryPresets = map[string]func(query *Query) bool{
+	// readonly matches the queries that only read rows
+	"readonly": func(query *Query) bool {
+		return query.Operation == "select"
+	},
+	// writes matches the queries that insert, update or delete rows
+	"writes": func(query *Query) bool {
+		return query.Operation != "select"
+	},
+	// crud matches the single-row queries by primary key and the plain
+	// table-wide queries, without their Exec and Batch forms
+	"crud": func(query *Query) bool {
+		return slices.Contains([]string{"Get", "Update", "Delete", "Insert", "List", "Count"}, query.Variant) &&
+			(query.Key == nil || query.Primary)
+	},
+	// batch matches the Batch queries
+	"batch": func(query *Query) bool {
+		return strings.HasPrefix(query.Variant, "Batch")
+	},
+	// exec matches the Exec queries, Batch ones included
+	"exec": func(query *Query) bool {
+		return strings.HasPrefix(strings.TrimPrefix(query.Variant, "Batch"), "Exec")
+	},
+	// joins matches the queries that embed a referenced row
+	"joins": func(query *Query) bool {
+		return query.Ref != nil
+	},
+	// unique-lookups matches the reads by unique index other than the
+	// primary key
+	"unique-lookups": func(query *Query) bool {
+		return query.Operation == "select" && query.Key != nil && !query.Primary && query.Key.Unique
+	},
+	// index-mutations matches the updates and deletes by non-unique index
+	"index-mutations": func(query *Query) bool {
+		return query.Operation != "select" && strings.HasSuffix(query.Variant, "By")
+	},
+	// all matches every query
+	"all": func(query *Query) bool {
+		return true
+	},
+}
+
+// withoutPresets returns the entries that are not presets (see QueryPresets).
+func (p Patterns) withoutPresets() Patterns {
+	patterns := make(Patterns, len(p))
+	for entry := range p {
+		if _, ok := QueryPresets[entry]; !ok {
+			patterns[entry] = true
+		}
+	}
+	return patterns
+}
+
+// Validate returns an error when an entry is not a valid pattern.
+func (p Patterns) Validate() error {
+	for entry := range p {
+		if expr, ok := strings.CutPrefix(entry, RegexpPrefix); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", entry, err)
+			}
+			continue
+		}
+
+		if _, err := path.Match(entry, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", entry, err)
+		}
+	}
+	return nil
+}
+
+// Match reports whether any of the names matches an entry.
+func (p Patterns) Match(names ...string) bool {
+	for _, name := range names {
+		// Exact names are the common case
+		if p[name] {
+			return true
+		}
+	}
+
+	for entry := range p {
+		for _, name := range names {
+			if matchPattern(entry, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchQuery reports whether the query matches an entry, presets included
+// (see QueryPresets).
+func (p Patterns) MatchQuery(query *Query) bool {
+	for entry := range p {
+		if preset, ok := QueryPresets[entry]; ok && preset(query) {
+			return true
+		}
+	}
+	return p.Match(query.Name)
+}
+
+// UnmatchedQueries returns the entries that match none of the queries,
+// sorted.
+func (p Patterns) UnmatchedQueries(queries []*Query) []string {
+	var entries []string
+	for entry := range p {
+		matches := func(query *Query) bool {
+			if preset, ok := QueryPresets[entry]; ok {
+				return preset(query)
+			}
+			return matchPattern(entry, query.Name)
+		}
+		if !slices.ContainsFunc(queries, matches) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+// Unmatched returns the entries that match none of the names, sorted.
+func (p Patterns) Unmatched(names []string) []string {
+	var entries []string
+	for entry := range p {
+		if !slices.ContainsFunc(names, func(name string) bool { return matchPattern(entry, name) }) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.Sort(entries)
+	return entries
+}
+
+// matchPattern reports whether the name matches the entry. Invalid patterns
+// match nothing (see Patterns.Validate).
+func matchPattern(entry, name string) bool {
+	if entry == name {
+		return true
+	}
+
+	if expr, ok := strings.CutPrefix(entry, RegexpPrefix); ok {
+		value, ok := expressions.Load(expr)
+		if !ok {
+			compiled, err := regexp.Compile(expr)
+			if err != nil {
+				return false
+			}
+			value, _ = expressions.LoadOrStore(expr, compiled)
+		}
+		return value.(*regexp.Regexp).MatchString(name)
+	}
+
+	ok, err := path.Match(entry, name)
+	return err == nil && ok
+}
+
+// suggest returns the name closest to the entry by edit distance, ignoring
+// case, or an empty string when none is close enough to be a likely typo.
+// Regular expressions get no suggestion.
+func suggest(entry string, names []string) string {
+	if strings.HasPrefix(entry, RegexpPrefix) {
+		return ""
+	}
+
+	var suggestion string
+	// Allow about one edit every four characters; later names must be closer
+	limit := len(entry)/4 + 1
+	for _, name := range names {
+		if distance := editDistance(strings.ToLower(entry), strings.ToLower(name)); distance <= limit {
+			suggestion, limit = name, distance-1
+		}
+	}
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	x, y := []rune(a), []rune(b)
+
+	row := make([]int, len(y)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			diagonal, row[j] = row[j], min(row[j]+1, row[j-1]+1, diagonal+cost)
+		}
+	}
+
+	return row[len(y)]
+}
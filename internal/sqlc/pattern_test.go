@@ -0,0 +1,116 @@
+package sqlc_test
+
+import (
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Patterns", func() {
+	Describe("Match", func() {
+		It("matches exact names", func() {
+			patterns := sqlc.Patterns{"users": true}
+			Expect(patterns.Match("users")).To(BeTrue())
+			Expect(patterns.Match("user")).To(BeFalse())
+		})
+
+		It("matches glob patterns", func() {
+			patterns := sqlc.Patterns{"audit_*": true, "auth.*": true, "*With*": true}
+			Expect(patterns.Match("audit_logs")).To(BeTrue())
+			Expect(patterns.Match("public.audit_logs")).To(BeFalse())
+			Expect(patterns.Match("accounts", "auth.accounts")).To(BeTrue())
+			Expect(patterns.Match("GetPostWithUser")).To(BeTrue())
+			Expect(patterns.Match("GetPost")).To(BeFalse())
+		})
+
+		It("matches regular expressions with the re: prefix", func() {
+			patterns := sqlc.Patterns{`re:^audit_\d+$`: true}
+			Expect(patterns.Match("audit_2024")).To(BeTrue())
+			Expect(patterns.Match("audit_logs")).To(BeFalse())
+		})
+
+		It("matches nothing when empty", func() {
+			Expect(sqlc.Patterns{}.Match("users")).To(BeFalse())
+		})
+	})
+
+	Describe("Validate", func() {
+		It("accepts names, globs and regular expressions", func() {
+			Expect(sqlc.Patterns{"users": true, "audit_*": true, "re:^a+$": true}.Validate()).To(Succeed())
+		})
+
+		It("rejects malformed patterns", func() {
+			Expect(sqlc.Patterns{"audit_[": true}.Validate()).To(MatchError(ContainSubstring(`invalid pattern "audit_["`)))
+			Expect(sqlc.Patterns{"re:(": true}.Validate()).To(MatchError(ContainSubstring(`invalid pattern "re:("`)))
+		})
+	})
+
+	Describe("Unmatched", func() {
+		It("returns the entries that match none of the names", func() {
+			patterns := sqlc.Patterns{"users": true, "audit_*": true, "re:^post": true}
+			Expect(patterns.Unmatched([]string{"users", "posts"})).To(Equal([]string{"audit_*"}))
+		})
+	})
+})
+
+var _ = Describe("QueryPresets", func() {
+	var queries []*sqlc.Query
+
+	BeforeEach(func() {
+		catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		generator := &sqlc.Generator{
+			Catalog: catalog,
+			Config: &sqlc.Config{
+				SQL: []sqlc.SQL{{Engine: "postgresql", Queries: "queries"}},
+			},
+		}
+
+		tables, err := generator.Queries(&generator.Config.SQL[0])
+		Expect(err).NotTo(HaveOccurred())
+
+		queries = nil
+		for _, table := range tables {
+			queries = append(queries, table.Queries...)
+			queries = append(queries, table.Skipped...)
+		}
+	})
+
+	// names returns the names of the queries matching the preset
+	names := func(preset string) []string {
+		var items []string
+		for _, query := range queries {
+			if (sqlc.Patterns{preset: true}).MatchQuery(query) {
+				items = append(items, query.Name)
+			}
+		}
+		return items
+	}
+
+	It("matches the queries by kind", func() {
+		Expect(names("readonly")).To(ContainElements("GetUser", "ListUsers", "CountPostsByUserId", "GetPostWithUser"))
+		Expect(names("readonly")).NotTo(ContainElement("InsertUser"))
+		Expect(names("writes")).To(ContainElements("InsertUser", "UpsertUserByEmail", "DeletePostsByTitle"))
+		Expect(names("writes")).NotTo(ContainElement("GetUser"))
+		Expect(names("crud")).To(ConsistOf(
+			"GetUser", "UpdateUser", "DeleteUser", "InsertUser", "ListUsers", "CountUsers",
+			"GetPost", "UpdatePost", "DeletePost", "InsertPost", "ListPosts", "CountPosts",
+		))
+		Expect(names("batch")).To(ContainElements("BatchGetUsers", "BatchExecInsertUsers"))
+		Expect(names("batch")).NotTo(ContainElement("ExecInsertUser"))
+		Expect(names("exec")).To(ContainElements("ExecInsertUser", "BatchExecInsertUsers"))
+		Expect(names("exec")).NotTo(ContainElement("BatchInsertUsers"))
+		Expect(names("joins")).To(ConsistOf("GetPostWithUser", "GetPostWithAuthor", "BatchGetPostsWithUser", "BatchGetPostsWithAuthor"))
+		Expect(names("unique-lookups")).To(ConsistOf("GetUserByEmail", "BatchGetUsersByEmail", "ExistsUserByEmail"))
+		Expect(names("index-mutations")).To(ContainElements("UpdatePostsByTitle", "ExecDeletePostsByUserId"))
+		Expect(names("index-mutations")).NotTo(ContainElement("DeletePost"))
+		Expect(names("all")).To(HaveLen(len(queries)))
+	})
+
+	It("reports the presets that match no query", func() {
+		patterns := sqlc.Patterns{"joins": true, "GetUser": true, "GetUsr": true}
+		Expect(patterns.UnmatchedQueries(queries)).To(Equal([]string{"GetUsr"}))
+	})
+})
@@ -0,0 +1,180 @@
+package sqlc
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// PluginMethod is the RPC method sqlc invokes on process plugins.
+const PluginMethod = "/plugin.CodegenService/Generate"
+
+// systemSchemas lists schemas in the sqlc catalog that hold engine
+// built-ins rather than user tables.
+var systemSchemas = map[string]bool{
+	"pg_catalog":         true,
+	"information_schema": true,
+}
+
+// RunPlugin implements the sqlc process-plugin protocol. It reads a protobuf
+// GenerateRequest from r, renders the queries and writes a protobuf
+// GenerateResponse to w.
+func RunPlugin(r io.Reader, w io.Writer) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	request := &plugin.GenerateRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		return fmt.Errorf("decoding the generate request: %w", err)
+	}
+
+	response, err := Plugin(request)
+	if err != nil {
+		return err
+	}
+
+	if data, err = proto.Marshal(response); err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// Plugin renders the queries for a sqlc GenerateRequest. The catalog is built
+// from the sqlc catalog in the request and the options from its plugin
+// options. File names in the response are relative to the codegen `out`
+// directory.
+func Plugin(request *plugin.GenerateRequest) (*plugin.GenerateResponse, error) {
+	var options CodegenOptions
+	// Plugin options are JSON, which is a subset of YAML
+	if len(request.PluginOptions) > 0 {
+		if err := yaml.Unmarshal(request.PluginOptions, &options); err != nil {
+			return nil, fmt.Errorf("decoding the plugin options: %w", err)
+		}
+	}
+
+	settings := request.GetSettings()
+
+	dialect, err := GetDialect(settings.GetEngine())
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := NewCatalog(request.GetCatalog())
+	// The sqlc catalog has no keys or defaults; take them from the schema DDL instead
+	schema := cmp.Or(request.GetCatalog().GetDefaultSchema(), dialect.Schema)
+	// Without them most queries would silently go missing from the output
+	ddl, err := LoadCatalogDDL(settings.GetEngine(), schema, settings.GetSchema()...)
+	if err != nil {
+		return nil, fmt.Errorf("reading the keys from the schema: %w", err)
+	}
+	catalog.MergeKeys(ddl)
+
+	generator := &Generator{
+		Catalog: catalog,
+		Config: &Config{
+			Version: settings.GetVersion(),
+			SQL: []SQL{
+				{
+					Schema: strings.Join(settings.GetSchema(), ","),
+					Engine: settings.GetEngine(),
+					Codegen: []Codegen{
+						{
+							Plugin:  PluginName,
+							Out:     settings.GetCodegen().GetOut(),
+							Options: options,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	files, err := generator.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &plugin.GenerateResponse{}
+	for _, file := range files {
+		// sqlc writes the files relative to the codegen `out` directory
+		name, err := filepath.Rel(settings.GetCodegen().GetOut(), file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		response.Files = append(response.Files, &plugin.File{
+			Name:     filepath.ToSlash(name),
+			Contents: file.Content,
+		})
+	}
+
+	return response, nil
+}
+
+// NewCatalog converts a sqlc plugin catalog into a Catalog. Engine built-in
+// schemas are skipped. The sqlc catalog only describes tables and columns;
+// it carries no primary key, index, foreign key or column default metadata
+// (see Catalog.MergeKeys).
+func NewCatalog(catalog *plugin.Catalog) *Catalog {
+	result := &Catalog{}
+
+	for _, schema := range catalog.GetSchemas() {
+		if systemSchemas[schema.GetName()] {
+			continue
+		}
+
+		item := Schema{
+			Name:       schema.GetName(),
+			Attributes: Attributes{Comment: schema.GetComment()},
+		}
+
+		for _, table := range schema.GetTables() {
+			entry := Table{
+				Name:       table.GetRel().GetName(),
+				Attributes: Attributes{Comment: table.GetComment()},
+			}
+
+			for _, column := range table.GetColumns() {
+				entry.Columns = append(entry.Columns, Column{
+					Name:       column.GetName(),
+					Type:       columnType(column),
+					Null:       !column.GetNotNull(),
+					Attributes: Attributes{Comment: column.GetComment()},
+				})
+			}
+
+			item.Tables = append(item.Tables, entry)
+		}
+
+		result.Schemas = append(result.Schemas, item)
+	}
+
+	return result
+}
+
+// columnType returns the type name of a sqlc catalog column. Types from
+// user schemas are schema-qualified.
+func columnType(column *plugin.Column) string {
+	kind := column.GetType()
+
+	name := kind.GetName()
+	if schema := kind.GetSchema(); schema != "" && !systemSchemas[schema] {
+		name = schema + "." + name
+	}
+
+	if column.GetIsArray() {
+		name += "[]"
+	}
+
+	return name
+}
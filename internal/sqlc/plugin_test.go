@@ -0,0 +1,156 @@
+package sqlc_test
+
+import (
+	"bytes"
+
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"google.golang.org/protobuf/proto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plugin", func() {
+	var request *plugin.GenerateRequest
+
+	BeforeEach(func() {
+		request = &plugin.GenerateRequest{
+			Settings: &plugin.Settings{
+				Version: "2",
+				Engine:  "postgresql",
+				Schema:  []string{"./ddl_test"},
+				Codegen: &plugin.Codegen{
+					Plugin: "gen-queries",
+					Out:    "ent/query",
+				},
+			},
+			Catalog: &plugin.Catalog{
+				DefaultSchema: "public",
+				Schemas: []*plugin.Schema{
+					{
+						Name: "pg_catalog",
+						Tables: []*plugin.Table{
+							{Rel: &plugin.Identifier{Schema: "pg_catalog", Name: "pg_class"}},
+						},
+					},
+					{
+						Name: "public",
+						Tables: []*plugin.Table{
+							{
+								Rel:     &plugin.Identifier{Schema: "public", Name: "users"},
+								Comment: "User accounts",
+								Columns: []*plugin.Column{
+									{
+										Name:    "id",
+										NotNull: true,
+										Type:    &plugin.Identifier{Schema: "pg_catalog", Name: "int4"},
+									},
+									{
+										Name:    "tags",
+										Type:    &plugin.Identifier{Name: "text"},
+										IsArray: true,
+									},
+									{
+										Name: "status",
+										Type: &plugin.Identifier{Schema: "public", Name: "user_status"},
+									},
+								},
+							},
+							{Rel: &plugin.Identifier{Schema: "public", Name: "posts"}},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	Describe("NewCatalog", func() {
+		It("converts the sqlc catalog", func() {
+			catalog := sqlc.NewCatalog(request.Catalog)
+			Expect(catalog.Schemas).To(HaveLen(1))
+			Expect(catalog.Schemas[0].Name).To(Equal("public"))
+			Expect(catalog.Schemas[0].Tables).To(HaveLen(2))
+
+			table := catalog.Schemas[0].Tables[0]
+			Expect(table.Name).To(Equal("users"))
+			Expect(table.Comment).To(Equal("User accounts"))
+			Expect(table.Columns).To(HaveLen(3))
+			Expect(table.Columns[0].Name).To(Equal("id"))
+			Expect(table.Columns[0].Type).To(Equal("int4"))
+			Expect(table.Columns[0].Null).To(BeFalse())
+			Expect(table.Columns[1].Type).To(Equal("text[]"))
+			Expect(table.Columns[1].Null).To(BeTrue())
+			Expect(table.Columns[2].Type).To(Equal("public.user_status"))
+		})
+	})
+
+	Describe("Plugin", func() {
+		It("returns one file per table", func() {
+			response, err := sqlc.Plugin(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Files).To(HaveLen(2))
+			Expect(response.Files[0].Name).To(Equal("users.sql"))
+			Expect(string(response.Files[0].Contents)).To(ContainSubstring("name: InsertUser :one"))
+			Expect(response.Files[1].Name).To(Equal("posts.sql"))
+		})
+
+		It("takes the keys from the schema DDL", func() {
+			response, err := sqlc.Plugin(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(response.Files[0].Contents)).To(ContainSubstring("name: GetUser :one"))
+			Expect(string(response.Files[0].Contents)).To(ContainSubstring("name: DeleteUser :one"))
+		})
+
+		When("the schema DDL cannot be read", func() {
+			It("returns an error", func() {
+				request.Settings.Schema = []string{"./missing"}
+
+				_, err := sqlc.Plugin(request)
+				Expect(err).To(MatchError(ContainSubstring("reading the keys from the schema")))
+			})
+		})
+
+		It("applies the plugin options", func() {
+			request.PluginOptions = []byte(`{"tables":{"exclude":["posts"]},"queries":{"exclude":["InsertUser"]}}`)
+
+			response, err := sqlc.Plugin(request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Files).To(HaveLen(1))
+			Expect(response.Files[0].Name).To(Equal("users.sql"))
+			Expect(string(response.Files[0].Contents)).NotTo(ContainSubstring("name: InsertUser :one"))
+			Expect(string(response.Files[0].Contents)).To(ContainSubstring("name: ExecInsertUser :exec"))
+		})
+
+		When("the plugin options are invalid", func() {
+			It("returns an error", func() {
+				request.PluginOptions = []byte(`{"tables": [`)
+				_, err := sqlc.Plugin(request)
+				Expect(err).To(MatchError(ContainSubstring("decoding the plugin options")))
+			})
+		})
+	})
+
+	Describe("RunPlugin", func() {
+		It("reads the request and writes the response", func() {
+			data, err := proto.Marshal(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			var output bytes.Buffer
+			Expect(sqlc.RunPlugin(bytes.NewReader(data), &output)).To(Succeed())
+
+			response := &plugin.GenerateResponse{}
+			Expect(proto.Unmarshal(output.Bytes(), response)).To(Succeed())
+			Expect(response.Files).To(HaveLen(2))
+		})
+
+		When("the request is malformed", func() {
+			It("returns an error", func() {
+				var output bytes.Buffer
+				err := sqlc.RunPlugin(bytes.NewReader([]byte{0xff, 0xff}), &output)
+				Expect(err).To(MatchError(ContainSubstring("decoding the generate request")))
+				Expect(output.Len()).To(BeZero())
+			})
+		})
+	})
+})
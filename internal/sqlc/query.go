@@ -0,0 +1,457 @@
+package sqlc
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/go-openapi/inflect"
+)
+
+// Query describes a query generated for a table. The queries of a table are
+// built before any SQL is rendered, so that they can be selected, checked for
+// collisions and listed without parsing the generated SQL.
+type Query struct {
+	// Name is the query name, e.g. GetUserByEmail.
+	Name string
+	// Variant identifies the SQL the template renders for the query, e.g.
+	// BatchExecUpdate. Variants of queries by non-unique index end in By.
+	Variant string
+	// Kind is the sqlc query annotation for the engine, e.g. :one.
+	Kind string
+	// Operation is select, insert, upsert, update or delete.
+	Operation string
+	// Key is the unique key or index the query filters by, if any.
+	Key *Index
+	// Primary reports whether Key is the primary key.
+	Primary bool
+	// Ref is the foreign key whose referenced row is embedded in the result.
+	Ref *ForeignKey
+	// Params lists the sqlc argument names of the query in order.
+	Params []string
+	// Result is the shape of the result: row, rows, embed, exists, count or
+	// none.
+	Result string
+	// Default reports whether the query is generated without being included.
+	Default bool
+	// Reason tells why the query is selected or skipped. It is set once the
+	// query options are applied (see Generator.Queries).
+	Reason Reason
+}
+
+// Section returns the section (reads or writes) of the query.
+func (x *Query) Section() string {
+	return querySection(x.Name)
+}
+
+// Matches reports whether the query is the given variant for the given key
+// and foreign key.
+func (x *Query) Matches(variant string, key *Index, ref *ForeignKey) bool {
+	if x.Variant != variant {
+		return false
+	}
+
+	if (x.Key == nil) != (key == nil) || (x.Ref == nil) != (ref == nil) {
+		return false
+	}
+
+	if key != nil && !slices.Equal(x.Key.Parts, key.Parts) {
+		return false
+	}
+
+	return ref == nil || x.Ref.Name == ref.Name
+}
+
+// queryVariant describes how the queries of a variant are named and run.
+type queryVariant struct {
+	// Prefix and Suffix surround the table name and the key suffix.
+	Prefix string
+	Suffix string
+	// Plural reports whether the table name is plural.
+	Plural bool
+	// Operation and Kind are passed to Dialect.Kind.
+	Operation string
+	Kind      string
+	// Result is the shape of the result when the kind returns rows.
+	Result string
+}
+
+// queryVariants holds the query variants keyed by name.
+var queryVariants = map[string]queryVariant{
+	// Unique keys
+	"Get":             {Prefix: "Get", Operation: "select", Kind: ":one", Result: "row"},
+	"GetWith":         {Prefix: "Get", Operation: "select", Kind: ":one", Result: "embed"},
+	"BatchGet":        {Prefix: "BatchGet", Plural: true, Operation: "select", Kind: ":batchone", Result: "row"},
+	"BatchGetWith":    {Prefix: "BatchGet", Plural: true, Operation: "select", Kind: ":batchone", Result: "embed"},
+	"Exists":          {Prefix: "Exists", Operation: "select", Kind: ":one", Result: "exists"},
+	"Update":          {Prefix: "Update", Operation: "update", Kind: ":one", Result: "row"},
+	"ExecUpdate":      {Prefix: "ExecUpdate", Operation: "update", Kind: ":exec"},
+	"BatchUpdate":     {Prefix: "BatchUpdate", Plural: true, Operation: "update", Kind: ":batchone", Result: "row"},
+	"BatchExecUpdate": {Prefix: "BatchExecUpdate", Plural: true, Operation: "update", Kind: ":batchexec"},
+	"Upsert":          {Prefix: "Upsert", Operation: "upsert", Kind: ":one", Result: "row"},
+	"ExecUpsert":      {Prefix: "ExecUpsert", Operation: "upsert", Kind: ":exec"},
+	"BatchUpsert":     {Prefix: "BatchUpsert", Plural: true, Operation: "upsert", Kind: ":batchone", Result: "row"},
+	"BatchExecUpsert": {Prefix: "BatchExecUpsert", Plural: true, Operation: "upsert", Kind: ":batchexec"},
+	"Delete":          {Prefix: "Delete", Operation: "delete", Kind: ":one", Result: "row"},
+	"ExecDelete":      {Prefix: "ExecDelete", Operation: "delete", Kind: ":exec"},
+	"BatchDelete":     {Prefix: "BatchDelete", Plural: true, Operation: "delete", Kind: ":batchone", Result: "row"},
+	"BatchExecDelete": {Prefix: "BatchExecDelete", Plural: true, Operation: "delete", Kind: ":batchexec"},
+	"Restore":         {Prefix: "Restore", Operation: "update", Kind: ":one", Result: "row"},
+	"HardDelete":      {Prefix: "HardDelete", Operation: "delete", Kind: ":one", Result: "row"},
+	// Tables
+	"Insert":          {Prefix: "Insert", Operation: "insert", Kind: ":one", Result: "row"},
+	"ExecInsert":      {Prefix: "ExecInsert", Operation: "insert", Kind: ":exec"},
+	"BatchInsert":     {Prefix: "BatchInsert", Plural: true, Operation: "insert", Kind: ":batchone", Result: "row"},
+	"BatchExecInsert": {Prefix: "BatchExecInsert", Plural: true, Operation: "insert", Kind: ":batchexec"},
+	"Copy":            {Prefix: "Copy", Plural: true, Operation: "insert", Kind: ":copyfrom"},
+	"List":            {Prefix: "List", Plural: true, Operation: "select", Kind: ":many", Result: "rows"},
+	"ListAfter":       {Prefix: "List", Suffix: "After", Plural: true, Operation: "select", Kind: ":many", Result: "rows"},
+	"Count":           {Prefix: "Count", Plural: true, Operation: "select", Kind: ":one", Result: "count"},
+	// Non-unique indexes
+	"ListBy":            {Prefix: "List", Plural: true, Operation: "select", Kind: ":many", Result: "rows"},
+	"ListByAfter":       {Prefix: "List", Suffix: "After", Plural: true, Operation: "select", Kind: ":many", Result: "rows"},
+	"CountBy":           {Prefix: "Count", Plural: true, Operation: "select", Kind: ":one", Result: "count"},
+	"UpdateBy":          {Prefix: "Update", Plural: true, Operation: "update", Kind: ":many", Result: "rows"},
+	"ExecUpdateBy":      {Prefix: "ExecUpdate", Plural: true, Operation: "update", Kind: ":execrows"},
+	"BatchUpdateBy":     {Prefix: "BatchUpdate", Plural: true, Operation: "update", Kind: ":batchmany", Result: "rows"},
+	"BatchExecUpdateBy": {Prefix: "BatchExecUpdate", Plural: true, Operation: "update", Kind: ":batchexec"},
+	"DeleteBy":          {Prefix: "Delete", Plural: true, Operation: "delete", Kind: ":many", Result: "rows"},
+	"ExecDeleteBy":      {Prefix: "ExecDelete", Plural: true, Operation: "delete", Kind: ":execrows"},
+	"BatchDeleteBy":     {Prefix: "BatchDelete", Plural: true, Operation: "delete", Kind: ":batchmany", Result: "rows"},
+	"BatchExecDeleteBy": {Prefix: "BatchExecDelete", Plural: true, Operation: "delete", Kind: ":batchexec"},
+}
+
+// rowKinds are the query annotations that return rows.
+var rowKinds = []string{":one", ":many", ":batchone", ":batchmany"}
+
+// queryBuilder builds the queries of a table in the order they are rendered.
+type queryBuilder struct {
+	Dialect    *Dialect
+	Naming     *Naming
+	Table      *Table
+	Name       string
+	SoftDelete *Column
+	CreatedAt  map[string]bool
+	UpdatedAt  map[string]bool
+	Readonly   map[string]bool
+
+	queries []*Query
+}
+
+// Build returns every query the template can render for the table, whether
+// selected or not.
+func (x *queryBuilder) Build() []*Query {
+	x.queries = nil
+
+	for _, key := range x.Table.GetUniqueKeys() {
+		primary := key == x.Table.PrimaryKey
+		params := x.keyParams(key)
+
+		x.add("Get", key, nil, primary, params)
+		if primary {
+			for _, fk := range x.Table.ForeignKeys {
+				x.add("GetWith", key, &fk, false, params)
+			}
+		}
+		x.add("BatchGet", key, nil, primary, params)
+		if primary {
+			for _, fk := range x.Table.ForeignKeys {
+				x.add("BatchGetWith", key, &fk, false, params)
+			}
+		}
+		x.add("Exists", key, nil, primary, params)
+
+		update := append(x.updateParams(x.Table.GetNonPrimaryKeyColumns()), params...)
+		for _, variant := range []string{"Update", "ExecUpdate", "BatchUpdate", "BatchExecUpdate"} {
+			x.add(variant, key, nil, primary, update)
+		}
+
+		upsert := x.valueParams(insertColumns(x.Readonly, key, x.Table.GetUpsertInsertColumns(key)))
+		for _, variant := range []string{"Upsert", "ExecUpsert", "BatchUpsert", "BatchExecUpsert"} {
+			x.add(variant, key, nil, false, upsert)
+		}
+
+		for _, variant := range []string{"Delete", "ExecDelete", "BatchDelete", "BatchExecDelete"} {
+			x.add(variant, key, nil, primary, params)
+		}
+
+		if x.SoftDelete != nil {
+			x.add("Restore", key, nil, primary, params)
+			x.add("HardDelete", key, nil, primary, params)
+		}
+	}
+
+	insert := x.valueParams(insertColumns(x.Readonly, nil, x.Table.GetInsertColumns()))
+	for _, variant := range []string{"Insert", "ExecInsert", "BatchInsert", "BatchExecInsert"} {
+		x.add(variant, nil, nil, true, insert)
+	}
+
+	var columns []string
+	for _, column := range insertColumns(x.Readonly, nil, x.Table.GetInsertColumns()) {
+		columns = append(columns, x.Dialect.Param(column.Name))
+	}
+	x.add("Copy", nil, nil, false, columns)
+
+	// Keyset pagination needs a primary key made of plain columns
+	keyset := x.Table.PrimaryKey != nil && !x.Table.PrimaryKey.HasExpr()
+
+	x.add("List", nil, nil, true, x.Dialect.LimitParams())
+	if keyset {
+		x.add("ListAfter", nil, nil, false, append(x.cursorParams(), "take"))
+	}
+	x.add("Count", nil, nil, true, nil)
+
+	for _, key := range x.Table.GetNonUniqueIndexes() {
+		foreign := x.Table.IsForeignKeyIndex(key)
+		params := x.keyParams(key)
+
+		x.add("ListBy", key, nil, foreign, append(slices.Clone(params), x.Dialect.LimitParams()...))
+		if keyset {
+			x.add("ListByAfter", key, nil, false, append(append(slices.Clone(params), x.cursorParams()...), "take"))
+		}
+		x.add("CountBy", key, nil, foreign, params)
+
+		update := append(x.updateParams(x.Table.Columns), params...)
+		for _, variant := range []string{"UpdateBy", "ExecUpdateBy", "BatchUpdateBy", "BatchExecUpdateBy"} {
+			x.add(variant, key, nil, false, update)
+		}
+
+		for _, variant := range []string{"DeleteBy", "ExecDeleteBy", "BatchDeleteBy", "BatchExecDeleteBy"} {
+			x.add(variant, key, nil, false, params)
+		}
+	}
+
+	return x.queries
+}
+
+// add appends a query of the given variant.
+func (x *queryBuilder) add(name string, key *Index, ref *ForeignKey, isDefault bool, params []string) {
+	variant := queryVariants[name]
+
+	query := &Query{
+		Variant:   name,
+		Kind:      x.Dialect.Kind(variant.Operation, variant.Kind),
+		Operation: variant.Operation,
+		Key:       key,
+		Primary:   key != nil && key == x.Table.PrimaryKey,
+		Ref:       ref,
+		Params:    unique(params),
+		Result:    variant.Result,
+		Default:   isDefault,
+	}
+
+	// Writes that cannot return rows on the engine return nothing
+	if !slices.Contains(rowKinds, query.Kind) {
+		query.Result = "none"
+	}
+
+	query.Name = variant.Prefix + x.Naming.tableName(x.Name, variant.Plural) + indexSuffix(key)
+	if ref != nil {
+		query.Name += "With" + x.Naming.tableName(tableRole(*ref), false)
+	}
+	query.Name += variant.Suffix
+
+	x.queries = append(x.queries, query)
+}
+
+// keyParams returns the arguments that select rows by the given key.
+func (x *queryBuilder) keyParams(key *Index) []string {
+	var params []string
+	for _, part := range key.Parts {
+		if column := x.Table.GetColumn(part.Column); column != nil {
+			params = append(params, x.Dialect.Param(column.Name))
+		}
+	}
+	return params
+}
+
+// cursorParams returns the after_<column> arguments of keyset pagination.
+func (x *queryBuilder) cursorParams() []string {
+	var params []string
+	for _, part := range x.Table.PrimaryKey.Parts {
+		params = append(params, x.Dialect.Param("after_"+part.Column))
+	}
+	return params
+}
+
+// valueParams returns the arguments of the inserted values. Timestamp
+// columns are set by the database.
+func (x *queryBuilder) valueParams(columns []Column) []string {
+	var params []string
+	for _, column := range columns {
+		if !x.CreatedAt[column.Name] && !x.UpdatedAt[column.Name] {
+			params = append(params, x.Dialect.Param(column.Name))
+		}
+	}
+	return params
+}
+
+// updateParams returns the update mask and value arguments of the updated
+// columns.
+func (x *queryBuilder) updateParams(columns []Column) []string {
+	var params []string
+	for _, column := range updateColumns(x.CreatedAt, x.Readonly, columns) {
+		if !x.UpdatedAt[column.Name] {
+			params = append(params, x.Dialect.UpdateMaskParam(column.Name), x.Dialect.Param(column.Name))
+		}
+	}
+	return params
+}
+
+// unique returns the arguments without repetitions, e.g. of the update mask
+// on engines with a single mask argument. sqlc binds an argument once.
+func unique(params []string) []string {
+	var items []string
+	for _, param := range params {
+		if !slices.Contains(items, param) {
+			items = append(items, param)
+		}
+	}
+	return items
+}
+
+// updateColumns returns the columns an UPDATE may set. Creation timestamps
+// never change once the row exists, and generated and read-only columns
+// cannot be written at all.
+func updateColumns(createdAt, readonly map[string]bool, columns []Column) []Column {
+	var items []Column
+	for _, column := range columns {
+		if !createdAt[column.Name] && !readonly[column.Name] && column.Generated == "" {
+			items = append(items, column)
+		}
+	}
+	return items
+}
+
+// insertColumns returns the columns an INSERT sets, leaving out the read-only
+// ones. The columns of the given key are kept, since an upsert must bind its
+// conflict target.
+func insertColumns(readonly map[string]bool, key *Index, columns []Column) []Column {
+	keyed := func(column Column) bool {
+		return key != nil && slices.ContainsFunc(key.Parts, func(part IndexPart) bool { return part.Column == column.Name })
+	}
+
+	var items []Column
+	for _, column := range columns {
+		if !readonly[column.Name] || keyed(column) {
+			items = append(items, column)
+		}
+	}
+	return items
+}
+
+// Reason tells why a query is selected or skipped.
+type Reason string
+
+const (
+	// ReasonDefault marks a query of the default set.
+	ReasonDefault Reason = "default"
+	// ReasonInclude marks an opt-in query listed in the queries include.
+	ReasonInclude Reason = "include"
+	// ReasonExclude marks a query listed in the queries exclude.
+	ReasonExclude Reason = "exclude"
+	// ReasonOptIn marks an opt-in query that is not included.
+	ReasonOptIn Reason = "opt-in"
+	// ReasonUnsupported marks a query whose kind the engine does not support.
+	ReasonUnsupported Reason = "unsupported"
+)
+
+// Selected reports whether queries with the reason render.
+func (r Reason) Selected() bool {
+	return r == ReasonDefault || r == ReasonInclude
+}
+
+// selectQueries sets the reason of every query and splits them into those
+// that render and those that are skipped: a query renders when it belongs to
+// the default set or is explicitly included, and never when it is excluded
+// (exclude wins). Queries whose kind the engine does not support are always
+// skipped.
+func selectQueries(dialect *Dialect, queries []*Query, include, exclude Patterns) (selected, skipped []*Query) {
+	for _, query := range queries {
+		query.Reason = queryReason(dialect, include, exclude, query)
+		if query.Reason.Selected() {
+			selected = append(selected, query)
+		} else {
+			skipped = append(skipped, query)
+		}
+	}
+	return selected, skipped
+}
+
+// querySelected reports whether the named query renders (see selectQueries).
+// Presets only match the queries of the query model, so the query is looked
+// up among them first, and the queries of custom templates are matched by
+// name only.
+func querySelected(dialect *Dialect, include, exclude Patterns, queries []*Query, name, kind string, isDefault bool) bool {
+	for _, query := range queries {
+		if query.Name == name {
+			return query.Reason.Selected()
+		}
+	}
+
+	query := &Query{Name: name, Kind: kind, Default: isDefault}
+	return queryReason(dialect, include.withoutPresets(), exclude.withoutPresets(), query).Selected()
+}
+
+// queryReason returns why the query is selected or skipped.
+func queryReason(dialect *Dialect, include, exclude Patterns, query *Query) Reason {
+	if exclude.MatchQuery(query) {
+		return ReasonExclude
+	}
+	if !query.Default && !include.MatchQuery(query) {
+		return ReasonOptIn
+	}
+	if !dialect.Supports(query.Kind) {
+		// Only warn when the query was asked for explicitly
+		level := slog.LevelDebug
+		if include.MatchQuery(query) {
+			level = slog.LevelWarn
+		}
+		slog.Log(context.Background(), level, "Skipping query not supported by the engine",
+			slog.String("query", query.Name),
+			slog.String("kind", query.Kind),
+			slog.String("engine", dialect.Engine),
+		)
+		return ReasonUnsupported
+	}
+	if query.Default {
+		return ReasonDefault
+	}
+	return ReasonInclude
+}
+
+// tableRole returns the role of the table referenced by a foreign key, named
+// after the foreign key column.
+func tableRole(fk ForeignKey) string {
+	column := fk.Columns[0]
+	// If the column name starts with the referenced table name, use that as the role
+	if strings.HasPrefix(column, fk.References.Table+"_") {
+		return fk.References.Table
+	}
+
+	suffixes := []string{"_id", "_fk", "_ref", "_key"}
+	// Remove common FK suffixes
+	for _, suffix := range suffixes {
+		column = strings.TrimSuffix(column, suffix)
+	}
+
+	return column
+}
+
+// indexSuffix returns the query name suffix for lookups by the given index,
+// e.g. ByTenantIdAndEmail. Primary key lookups and table-wide queries have
+// none.
+func indexSuffix(index *Index) string {
+	// Don't add suffix for primary key lookups
+	if index == nil || index.Name == "primary key" {
+		return ""
+	}
+
+	var items []string
+	// Build the suffix based on index parts
+	for _, part := range index.Parts {
+		items = append(items, inflect.Camelize(part.Column))
+	}
+	return "By" + strings.Join(items, "And")
+}
@@ -0,0 +1,182 @@
+package sqlc_test
+
+import (
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Query", func() {
+	var generator *sqlc.Generator
+
+	BeforeEach(func() {
+		catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		generator = &sqlc.Generator{
+			Catalog: catalog,
+			Config: &sqlc.Config{
+				SQL: []sqlc.SQL{
+					{Engine: "postgresql", Queries: "queries"},
+				},
+			},
+		}
+	})
+
+	// find returns the query with the given name of the given table
+	find := func(tables []*sqlc.TableQueries, table, name string) *sqlc.Query {
+		for _, item := range tables {
+			if item.Table.Name != table {
+				continue
+			}
+			for _, query := range item.Queries {
+				if query.Name == name {
+					return query
+				}
+			}
+		}
+		return nil
+	}
+
+	Describe("Generator.Queries", func() {
+		It("builds the default queries of every table", func() {
+			tables, err := generator.Queries(&generator.Config.SQL[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tables).To(HaveLen(2))
+			Expect(tables[0].Name).To(Equal("users"))
+
+			query := find(tables, "users", "GetUser")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Kind).To(Equal(":one"))
+			Expect(query.Operation).To(Equal("select"))
+			Expect(query.Key.Parts[0].Column).To(Equal("id"))
+			Expect(query.Params).To(Equal([]string{"id"}))
+			Expect(query.Result).To(Equal("row"))
+			Expect(query.Default).To(BeTrue())
+			Expect(query.Section()).To(Equal("reads"))
+
+			query = find(tables, "users", "UpdateUser")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Params).To(Equal([]string{"update_mask", "email", "name", "id"}))
+
+			query = find(tables, "users", "ListUsers")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Key).To(BeNil())
+			Expect(query.Params).To(Equal([]string{"take", "skip"}))
+			Expect(query.Result).To(Equal("rows"))
+
+			query = find(tables, "posts", "ListPostsByUserId")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Variant).To(Equal("ListBy"))
+			Expect(query.Params).To(Equal([]string{"user_id", "take", "skip"}))
+		})
+
+		It("leaves out opt-in queries unless included", func() {
+			tables, err := generator.Queries(&generator.Config.SQL[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(find(tables, "posts", "GetPostWithUser")).To(BeNil())
+
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin: "gen-queries",
+					Options: sqlc.CodegenOptions{
+						Queries: sqlc.QueryOptions{
+							Include: []string{"GetPostWithUser"},
+							Exclude: []string{"GetUser"},
+						},
+					},
+				},
+			}
+
+			tables, err = generator.Queries(&generator.Config.SQL[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(find(tables, "users", "GetUser")).To(BeNil())
+
+			query := find(tables, "posts", "GetPostWithUser")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Ref.References.Table).To(Equal("users"))
+			Expect(query.Result).To(Equal("embed"))
+			Expect(query.Default).To(BeFalse())
+		})
+
+		It("records why each query is selected or skipped", func() {
+			generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+				{
+					Plugin: "gen-queries",
+					Options: sqlc.CodegenOptions{
+						Queries: sqlc.QueryOptions{
+							Include: []string{"GetPostWithUser"},
+							Exclude: []string{"GetUser"},
+						},
+					},
+				},
+			}
+
+			tables, err := generator.Queries(&generator.Config.SQL[0])
+			Expect(err).NotTo(HaveOccurred())
+
+			reasons := make(map[string]sqlc.Reason)
+			for _, table := range tables {
+				for _, query := range table.Queries {
+					Expect(query.Reason.Selected()).To(BeTrue())
+					reasons[query.Name] = query.Reason
+				}
+				for _, query := range table.Skipped {
+					Expect(query.Reason.Selected()).To(BeFalse())
+					reasons[query.Name] = query.Reason
+				}
+			}
+
+			Expect(reasons).To(HaveKeyWithValue("ListUsers", sqlc.ReasonDefault))
+			Expect(reasons).To(HaveKeyWithValue("GetPostWithUser", sqlc.ReasonInclude))
+			Expect(reasons).To(HaveKeyWithValue("GetUser", sqlc.ReasonExclude))
+			Expect(reasons).To(HaveKeyWithValue("CopyUsers", sqlc.ReasonOptIn))
+		})
+
+		It("maps the kinds to the engine", func() {
+			generator.Config.SQL[0].Engine = "mysql"
+
+			tables, err := generator.Queries(&generator.Config.SQL[0])
+			Expect(err).NotTo(HaveOccurred())
+
+			query := find(tables, "users", "InsertUser")
+			Expect(query).NotTo(BeNil())
+			Expect(query.Kind).To(Equal(":execlastid"))
+			Expect(query.Result).To(Equal("none"))
+			// Batch queries are not supported by the engine
+			Expect(find(tables, "users", "BatchGetUsers")).To(BeNil())
+		})
+
+		When("two tables generate the same query", func() {
+			It("returns an error", func() {
+				generator.Catalog.Schemas[0].Tables = append(generator.Catalog.Schemas[0].Tables, sqlc.Table{
+					Name:       "user_by_email",
+					Columns:    []sqlc.Column{{Name: "id"}},
+					PrimaryKey: &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}},
+				})
+				generator.Config.SQL[0].Codegen = []sqlc.Codegen{
+					{
+						Plugin:  "gen-queries",
+						Options: sqlc.CodegenOptions{Queries: sqlc.QueryOptions{Include: []string{"GetUserByEmail"}}},
+					},
+				}
+
+				_, err := generator.Queries(&generator.Config.SQL[0])
+				Expect(err).To(MatchError("query GetUserByEmail is generated for both public.users and public.user_by_email"))
+			})
+		})
+	})
+
+	Describe("Matches", func() {
+		key := &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "email"}}}
+		query := &sqlc.Query{Variant: "Get", Key: key}
+
+		It("matches the variant and the key parts", func() {
+			Expect(query.Matches("Get", &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "email"}}}, nil)).To(BeTrue())
+			Expect(query.Matches("Get", &sqlc.Index{Parts: []sqlc.IndexPart{{Column: "id"}}}, nil)).To(BeFalse())
+			Expect(query.Matches("Exists", key, nil)).To(BeFalse())
+			Expect(query.Matches("Get", nil, nil)).To(BeFalse())
+		})
+	})
+})
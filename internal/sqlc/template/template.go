@@ -3,6 +3,9 @@ package template
 
 import (
 	"embed"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -27,3 +30,38 @@ func Open(name string, opts ...map[string]any) (*template.Template, error) {
 
 	return file.ParseFS(fs, name)
 }
+
+// OpenDir loads the template file from the embedded filesystem and then parses
+// the *.tmpl files in dir on top of it. A file named after the template
+// replaces it entirely, while {{define}} actions replace the named blocks of
+// the template and keep the built-in ones for everything else. An empty dir
+// only loads the embedded template.
+func OpenDir(dir, name string, opts ...map[string]any) (*template.Template, error) {
+	file, err := Open(name, opts...)
+	if err != nil || dir == "" {
+		return file, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", dir)
+	}
+
+	// Parse the replacement of the template first so that the blocks
+	// defined in the other files take precedence over its own
+	slices.SortStableFunc(paths, func(x, y string) int {
+		switch {
+		case filepath.Base(x) == name:
+			return -1
+		case filepath.Base(y) == name:
+			return 1
+		}
+		return 0
+	})
+
+	return file.ParseFiles(paths...)
+}
@@ -1,7 +1,10 @@
 package template_test
 
 import (
+	"bytes"
 	"maps"
+	"os"
+	"path/filepath"
 
 	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc/template"
 
@@ -13,16 +16,24 @@ var _ = Describe("Template", func() {
 	Describe("Open", func() {
 		opts := map[string]any{
 			// Table Functions
-			"table_ref":   func(args ...any) string { return "" },
-			"table_name":  func(args ...any) string { return "" },
-			"table_join":  func(args ...any) string { return "" },
-			"table_embed": func(args ...any) string { return "" },
+			"table_ref":     func(args ...any) string { return "" },
+			"table_name":    func(args ...any) string { return "" },
+			"table_join":    func(args ...any) string { return "" },
+			"table_embed":   func(args ...any) string { return "" },
+			"table_columns": func(args ...any) string { return "" },
+			"with_key":      func(args ...any) any { return nil },
 			// Query Functions
 			"query_condition": func(args ...any) string { return "" },
 			"query_argument":  func(args ...any) string { return "" },
 			"query_index":     func(args ...any) string { return "" },
+			"query_lookup":    func(args ...any) any { return nil },
+			"query_value":     func(args ...any) string { return "" },
+			"update_columns":  func(args ...any) []any { return nil },
+			"insert_columns":  func(args ...any) []any { return nil },
+			"is_updated_at":   func(args ...any) bool { return false },
 			// Pagination Functions
-			"query_order": func(args ...any) string { return "" },
+			"query_order":  func(args ...any) string { return "" },
+			"query_cursor": func(args ...any) string { return "" },
 			// Foreign key index check
 			"is_fk_index": func(args ...any) bool { return false },
 			// Query selection function
@@ -55,6 +66,41 @@ var _ = Describe("Template", func() {
 			Expect(file).NotTo(BeNil())
 		})
 
+		Describe("OpenDir", func() {
+			var dir string
+
+			BeforeEach(func() {
+				dir = GinkgoT().TempDir()
+			})
+
+			It("replaces the named blocks", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "update.tmpl"), []byte(`{{define "Update"}}-- custom update{{end}}`), 0o600)).To(Succeed())
+
+				file, err := template.OpenDir(dir, "template.sql.tmpl", opts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(file.Lookup("Update").Tree.Root.String()).To(Equal("-- custom update"))
+				Expect(file.Lookup("Get")).NotTo(BeNil())
+			})
+
+			It("replaces the whole template", func() {
+				Expect(os.WriteFile(filepath.Join(dir, "template.sql.tmpl"), []byte(`-- custom`), 0o600)).To(Succeed())
+
+				file, err := template.OpenDir(dir, "template.sql.tmpl", opts)
+				Expect(err).NotTo(HaveOccurred())
+
+				var buffer bytes.Buffer
+				Expect(file.Execute(&buffer, nil)).To(Succeed())
+				Expect(buffer.String()).To(Equal("-- custom"))
+			})
+
+			When("the directory has no templates", func() {
+				It("returns an error", func() {
+					_, err := template.OpenDir(dir, "template.sql.tmpl", opts)
+					Expect(err).To(MatchError(ContainSubstring("no templates found")))
+				})
+			})
+		})
+
 		When("the template does not exist", func() {
 			It("returns an error", func() {
 				_, err := template.Open("nonexistent.tmpl")
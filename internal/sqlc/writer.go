@@ -0,0 +1,119 @@
+package sqlc
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Writer writes the rendered files (see Generator.Generate).
+type Writer interface {
+	// WriteFile writes a rendered file.
+	WriteFile(file *File) error
+	// RemoveFile removes a stale generated file.
+	RemoveFile(path string) error
+}
+
+// DirWriter writes the files to disk, relative to the working directory. It
+// is the default Writer of the Generator.
+type DirWriter struct{}
+
+// WriteFile implements Writer.
+func (x *DirWriter) WriteFile(file *File) error {
+	if err := os.MkdirAll(filepath.Dir(file.Path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file.Path, file.Content, 0o666)
+}
+
+// RemoveFile implements Writer.
+func (x *DirWriter) RemoveFile(path string) error {
+	slog.Info("Removing a stale generated file", slog.String("path", path))
+	return os.Remove(path)
+}
+
+// DryRunWriter prints the files and queries that would be written, with the
+// reason each query is selected or skipped, without touching the
+// filesystem.
+type DryRunWriter struct {
+	// Writer is where the report is printed.
+	Writer io.Writer
+}
+
+// WriteFile implements Writer.
+func (x *DryRunWriter) WriteFile(file *File) error {
+	if _, err := fmt.Fprintf(x.Writer, "write %s\n", file.Path); err != nil {
+		return err
+	}
+
+	for _, table := range file.Tables {
+		if _, err := fmt.Fprintf(x.Writer, "  %s.%s: %d queries, %d skipped\n",
+			table.Schema, table.Table.Name, len(table.Queries), len(table.Skipped)); err != nil {
+			return err
+		}
+
+		for _, query := range table.Queries {
+			if _, err := fmt.Fprintf(x.Writer, "    + %s (%s)\n", query.Name, query.Reason); err != nil {
+				return err
+			}
+		}
+
+		for _, query := range table.Skipped {
+			if _, err := fmt.Fprintf(x.Writer, "    - %s (%s)\n", query.Name, query.Reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RemoveFile implements Writer.
+func (x *DryRunWriter) RemoveFile(path string) error {
+	_, err := fmt.Fprintf(x.Writer, "remove %s\n", path)
+	return err
+}
+
+// StdoutWriter prints the rendered SQL instead of writing the files. Stale
+// files are left alone.
+type StdoutWriter struct {
+	// Writer is where the SQL is printed.
+	Writer io.Writer
+	// Table limits the output to the SQL of the table, given by plain or
+	// schema-qualified name, even in files shared with other tables. Every
+	// file is printed when empty.
+	Table string
+
+	// found tells whether a file holds the Table.
+	found bool
+}
+
+// WriteFile implements Writer.
+func (x *StdoutWriter) WriteFile(file *File) error {
+	if x.Table != "" {
+		if file = file.Filter(x.Table); file == nil {
+			return nil
+		}
+		x.found = true
+	}
+
+	_, err := x.Writer.Write(file.Content)
+	return err
+}
+
+// Close returns an error when the Table is set but no file holds it, such as
+// an unknown or excluded table.
+func (x *StdoutWriter) Close() error {
+	if x.Table != "" && !x.found {
+		return fmt.Errorf("table %q is not generated", x.Table)
+	}
+	return nil
+}
+
+// RemoveFile implements Writer.
+func (x *StdoutWriter) RemoveFile(path string) error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package sqlc_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/sqlc-contrib/sqlc-gen-queries/internal/sqlc"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		dir       string
+		buffer    *bytes.Buffer
+		generator *sqlc.Generator
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "sqlc-gen-writer-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		catalog, err := sqlc.LoadCatalog("./catalog_test.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		buffer = &bytes.Buffer{}
+		generator = &sqlc.Generator{
+			Catalog: catalog,
+			Config: &sqlc.Config{
+				Version: "2",
+				SQL: []sqlc.SQL{
+					{
+						Engine:  "postgresql",
+						Queries: dir,
+						Codegen: []sqlc.Codegen{
+							{
+								Plugin: "gen-queries",
+								Options: sqlc.CodegenOptions{
+									Queries: sqlc.QueryOptions{
+										Include: []string{"GetPostWithUser"},
+										Exclude: []string{"DeleteUser"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Describe("DryRunWriter", func() {
+		BeforeEach(func() {
+			generator.Writer = &sqlc.DryRunWriter{Writer: buffer}
+		})
+
+		It("prints the files and queries with their reasons", func() {
+			Expect(generator.Generate()).To(Succeed())
+
+			output := buffer.String()
+			Expect(output).To(ContainSubstring("write " + filepath.Join(dir, "users.sql") + "\n  public.users: "))
+			Expect(output).To(ContainSubstring("    + GetUser (default)\n"))
+			Expect(output).To(ContainSubstring("    + GetPostWithUser (include)\n"))
+			Expect(output).To(ContainSubstring("    - DeleteUser (exclude)\n"))
+			Expect(output).To(ContainSubstring("    - CopyUsers (opt-in)\n"))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+
+		It("prints the stale files that would be removed", func() {
+			path := filepath.Join(dir, "comments.sql")
+			Expect(os.WriteFile(path, []byte(sqlc.Header), 0o666)).To(Succeed())
+
+			Expect(generator.Generate()).To(Succeed())
+			Expect(buffer.String()).To(ContainSubstring("remove " + path + "\n"))
+			Expect(path).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("StdoutWriter", func() {
+		It("prints the SQL of every file", func() {
+			generator.Writer = &sqlc.StdoutWriter{Writer: buffer}
+			Expect(generator.Generate()).To(Succeed())
+
+			Expect(buffer.String()).To(ContainSubstring("-- name: GetUser :one"))
+			Expect(buffer.String()).To(ContainSubstring("-- name: GetPost :one"))
+			Expect(filepath.Join(dir, "users.sql")).NotTo(BeAnExistingFile())
+		})
+
+		It("prints the SQL of the given table only", func() {
+			generator.Writer = &sqlc.StdoutWriter{Writer: buffer, Table: "public.posts"}
+			Expect(generator.Generate()).To(Succeed())
+
+			Expect(buffer.String()).To(HavePrefix(sqlc.Header))
+			Expect(buffer.String()).To(ContainSubstring("-- name: GetPost :one"))
+			Expect(buffer.String()).NotTo(ContainSubstring("-- name: GetUser :one"))
+		})
+
+		It("prints the SQL of the given table only from a shared file", func() {
+			generator.Config.SQL[0].Codegen[0].Options.Layout = "single-file"
+			writer := &sqlc.StdoutWriter{Writer: buffer, Table: "posts"}
+			generator.Writer = writer
+			Expect(generator.Generate()).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+
+			Expect(buffer.String()).To(HavePrefix(sqlc.Header))
+			Expect(buffer.String()).To(ContainSubstring("-- name: GetPost :one"))
+			Expect(buffer.String()).NotTo(ContainSubstring("-- name: GetUser :one"))
+		})
+
+		It("returns an error when no file holds the table", func() {
+			writer := &sqlc.StdoutWriter{Writer: buffer, Table: "comments"}
+			generator.Writer = writer
+			Expect(generator.Generate()).To(Succeed())
+
+			Expect(writer.Close()).To(MatchError(`table "comments" is not generated`))
+			Expect(buffer.String()).To(BeEmpty())
+		})
+	})
+})